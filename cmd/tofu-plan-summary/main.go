@@ -0,0 +1,142 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command tofu-plan-summary prints a concise summary of an OpenTofu plan,
+// suitable for posting as a pull request comment.
+//
+// Usage:
+//
+//	tofu-plan-summary [flags] [plan]
+//
+// The plan is either the JSON output of tofu show -json, or a saved plan
+// file, in which case tofu show is run in the -chdir directory. Without a
+// plan argument, or with "-", JSON is read from standard input:
+//
+//	tofu show -json plan.tfplan | tofu-plan-summary -max-length 65536 > comment.md
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+	"github.com/opentofu/tofu-exec/tfexec/planrender"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "tofu-plan-summary: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("tofu-plan-summary", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "output format, markdown or text")
+	title := flags.String("title", "", "title rendered above the summary")
+	maxLength := flags.Int("max-length", 0, "maximum length of the output in bytes, 0 for no limit")
+	chdir := flags.String("chdir", ".", "working directory to run tofu show in, for saved plan files")
+	tofu := flags.String("tofu", "", "path to the tofu executable, for saved plan files (default: tofu from PATH)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("expected at most one plan argument, got %d", flags.NArg())
+	}
+
+	opts := planrender.Options{
+		Title:     *title,
+		MaxLength: *maxLength,
+	}
+	switch *format {
+	case "markdown":
+		opts.Format = planrender.FormatMarkdown
+	case "text":
+		opts.Format = planrender.FormatText
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	var plan *tfjson.Plan
+	path := flags.Arg(0)
+	if path == "" || path == "-" {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		plan, err = parsePlan(b)
+		if err != nil {
+			return err
+		}
+	} else {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if isJSON(b) {
+			plan, err = parsePlan(b)
+		} else {
+			plan, err = showPlanFile(*tofu, *chdir, path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(stdout, planrender.Render(plan, opts))
+	return err
+}
+
+func isJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{'
+}
+
+func parsePlan(b []byte) (*tfjson.Plan, error) {
+	var plan tfjson.Plan
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return nil, fmt.Errorf("unable to parse plan JSON: %w", err)
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func showPlanFile(execPath string, workingDir string, path string) (*tfjson.Plan, error) {
+	if execPath == "" {
+		var err error
+		execPath, err = exec.LookPath("tofu")
+		if err != nil {
+			return nil, fmt.Errorf("unable to find tofu executable, use -tofu to specify its path: %w", err)
+		}
+	}
+
+	// tofu show runs in the working directory
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := tfexec.NewTofu(workingDir, execPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return tf.ShowPlanFile(ctx, path)
+}
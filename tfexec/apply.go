@@ -17,11 +17,14 @@ type applyConfig struct {
 	backup    string
 	destroy   bool
 	dirOrPlan string
+	excludes  []string
 	lock      bool
 
 	// LockTimeout must be a string with time unit, e.g. '10s'
 	lockTimeout  string
 	parallelism  int
+	policies     []Policy
+	provenance   bool
 	reattachInfo ReattachInfo
 	refresh      bool
 	refreshOnly  bool
@@ -29,6 +32,7 @@ type applyConfig struct {
 	state        string
 	stateOut     string
 	targets      []string
+	typedVars    []*VarValueOption
 
 	// Vars: each var must be supplied as a single string, e.g. 'foo=bar'
 	vars     []string
@@ -59,6 +63,10 @@ func (opt *TargetOption) configureApply(conf *applyConfig) {
 	conf.targets = append(conf.targets, opt.target)
 }
 
+func (opt *ExcludeOption) configureApply(conf *applyConfig) {
+	conf.excludes = append(conf.excludes, opt.exclude)
+}
+
 func (opt *LockTimeoutOption) configureApply(conf *applyConfig) {
 	conf.lockTimeout = opt.timeout
 }
@@ -91,6 +99,10 @@ func (opt *ReplaceOption) configureApply(conf *applyConfig) {
 	conf.replaceAddrs = append(conf.replaceAddrs, opt.address)
 }
 
+func (opt *VarValueOption) configureApply(conf *applyConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
 func (opt *VarOption) configureApply(conf *applyConfig) {
 	conf.vars = append(conf.vars, opt.assignment)
 }
@@ -107,8 +119,21 @@ func (opt *DestroyFlagOption) configureApply(conf *applyConfig) {
 	conf.destroy = opt.destroy
 }
 
+func (opt *PoliciesOption) configureApply(conf *applyConfig) {
+	conf.policies = append(conf.policies, opt.policies...)
+}
+
+func (opt *ProvenanceOption) configureApply(conf *applyConfig) {
+	conf.provenance = opt.provenance
+}
+
 // Apply represents the tofu apply subcommand.
 func (tf *Tofu) Apply(ctx context.Context, opts ...ApplyOption) error {
+	err := tf.checkApply(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
 	cmd, err := tf.applyCmd(ctx, opts...)
 	if err != nil {
 		return err
@@ -121,16 +146,70 @@ func (tf *Tofu) Apply(ctx context.Context, opts ...ApplyOption) error {
 // JSON being written to the supplied `io.Writer`. ApplyJSON is likely to be
 // removed in a future major version in favour of Apply returning JSON by default.
 func (tf *Tofu) ApplyJSON(ctx context.Context, w io.Writer, opts ...ApplyOption) error {
-	tf.SetStdout(w)
+	err := tf.checkApply(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := tf.applyJSONCmd(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = mergeWriters(cmd.Stdout, w)
+
+	return tf.runTofuCmd(ctx, cmd)
+}
+
+// ApplyEvents represents the tofu apply subcommand with the `-json` flag.
+// The handler is called with every machine-readable UI message as it is
+// emitted, see Event for the available message types.
+func (tf *Tofu) ApplyEvents(ctx context.Context, handler EventHandler, opts ...ApplyOption) error {
+	err := tf.checkApply(ctx, opts...)
+	if err != nil {
+		return err
+	}
 
 	cmd, err := tf.applyJSONCmd(ctx, opts...)
 	if err != nil {
 		return err
 	}
 
+	cmd.Stdout = mergeWriters(cmd.Stdout, newEventWriter(handler))
+
 	return tf.runTofuCmd(ctx, cmd)
 }
 
+// checkApply verifies the provenance of the saved plan and evaluates the
+// policies against it, as requested by the Provenance and Policies options.
+// Both run commands of their own, so this is done before building the apply
+// command.
+func (tf *Tofu) checkApply(ctx context.Context, opts ...ApplyOption) error {
+	c := defaultApplyOptions
+
+	for _, o := range opts {
+		o.configureApply(&c)
+	}
+
+	if c.provenance {
+		if c.dirOrPlan == "" {
+			return fmt.Errorf("the Provenance option requires a saved plan passed with the DirOrPlan option")
+		}
+		err := tf.verifyPlanProvenance(ctx, c.dirOrPlan, c.state)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.policies) > 0 {
+		err := tf.checkPolicies(ctx, c.policies, c.dirOrPlan, c.reattachInfo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (tf *Tofu) applyCmd(ctx context.Context, opts ...ApplyOption) (*exec.Cmd, error) {
 	c := defaultApplyOptions
 
@@ -210,22 +289,37 @@ func (tf *Tofu) buildApplyArgs(c applyConfig) ([]string, error) {
 			args = append(args, "-target="+ta)
 		}
 	}
+	if c.excludes != nil {
+		for _, ex := range c.excludes {
+			args = append(args, "-exclude="+ex)
+		}
+	}
 	if c.vars != nil {
 		for _, v := range c.vars {
 			args = append(args, "-var", v)
 		}
 	}
+	args = append(args, typedVarArgs(c.typedVars)...)
 
 	return args, nil
 }
 
 func (tf *Tofu) buildApplyCmd(ctx context.Context, c applyConfig, args []string) (*exec.Cmd, error) {
+	err := tf.checkExcludes(ctx, c.targets, c.excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	// string argument: pass if set
 	if c.dirOrPlan != "" {
 		args = append(args, c.dirOrPlan)
 	}
 
 	mergeEnv := map[string]string{}
+	err = addTypedVarEnv(mergeEnv, c.typedVars)
+	if err != nil {
+		return nil, err
+	}
 	if c.reattachInfo != nil {
 		reattachStr, err := c.reattachInfo.marshalString()
 		if err != nil {
@@ -7,6 +7,8 @@ package tfexec
 
 import (
 	"context"
+	"errors"
+	"os"
 	"testing"
 
 	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
@@ -91,6 +93,31 @@ func TestApplyCmd(t *testing.T) {
 			"-refresh-only",
 		}, nil, applyCmd)
 	})
+	t.Run("exclude", func(t *testing.T) {
+		applyCmd, err := tf.applyCmd(context.Background(), Exclude("null_resource.foo"), Exclude("module.bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"apply",
+			"-no-color",
+			"-auto-approve",
+			"-input=false",
+			"-lock=true",
+			"-parallelism=10",
+			"-refresh=true",
+			"-exclude=null_resource.foo",
+			"-exclude=module.bar",
+		}, nil, applyCmd)
+	})
+
+	t.Run("exclude with target", func(t *testing.T) {
+		_, err := tf.applyCmd(context.Background(), Target("null_resource.foo"), Exclude("module.bar"))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
 }
 
 func TestApplyJSONCmd(t *testing.T) {
@@ -152,3 +179,40 @@ func TestApplyJSONCmd(t *testing.T) {
 		}, nil, applyCmd)
 	})
 }
+
+func TestCheckApply(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no checks", func(t *testing.T) {
+		err := tf.checkApply(context.Background(), DirOrPlan("planfile"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("provenance without plan", func(t *testing.T) {
+		err := tf.checkApply(context.Background(), Provenance(true))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+
+	t.Run("policies without plan", func(t *testing.T) {
+		err := tf.checkApply(context.Background(), Policies(DenyReplace()))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+
+	t.Run("provenance without record", func(t *testing.T) {
+		err := tf.checkApply(context.Background(), DirOrPlan("planfile"), Provenance(true))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected missing provenance record error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// CancelStage is the stage of the cancellation of a command which ended the
+// OpenTofu process, see SetCancelGracePeriod and CancelStageOf.
+type CancelStage string
+
+const (
+	// CancelStageNone means the process was not ended by the cancellation,
+	// for example because it exited before the context was done.
+	CancelStageNone CancelStage = ""
+
+	// CancelStageInterrupt means the process exited after being interrupted,
+	// within the grace period.
+	CancelStageInterrupt CancelStage = "interrupt"
+
+	// CancelStageKill means the process was killed, either immediately or
+	// after the grace period expired.
+	CancelStageKill CancelStage = "kill"
+)
+
+// SetCancelGracePeriod configures how commands are cancelled when their
+// context is done.
+//
+// By default the grace period is zero and the OpenTofu process is killed
+// immediately, which can leave the state locked or partially written. With a
+// positive grace period, the process is interrupted first, like pressing
+// Ctrl-C, giving OpenTofu the chance to stop gracefully, persist the state
+// and release locks. Only if it did not exit within the grace period, it is
+// killed. The command returns once the process exited.
+//
+// On platforms which do not support interrupting processes, such as Windows,
+// the process is always killed immediately.
+//
+// On Linux, OpenTofu is started in its own process group if a grace period
+// is set, so that the interrupt and the kill reach the provider plugins too.
+// It then no longer receives the signals a terminal sends to the calling
+// process, such as on Ctrl-C. OpenTofu is killed if the calling process dies,
+// whether or not a grace period is set.
+func (tf *Tofu) SetCancelGracePeriod(gracePeriod time.Duration) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.cancelGracePeriod = gracePeriod
+}
+
+// CancelStageOf returns the stage of the cancellation which ended the process
+// of the command which returned err.
+func CancelStageOf(err error) CancelStage {
+	var cErr cmdErr
+	if errors.As(err, &cErr) {
+		return cErr.stage
+	}
+	return CancelStageNone
+}
+
+// processCanceler cancels a running command, escalating from interrupting
+// the process to killing it after the grace period.
+type processCanceler struct {
+	cmd         *exec.Cmd
+	gracePeriod time.Duration
+
+	// outputCtx is done once the process is killed or has exited, so that
+	// output can still be read while OpenTofu stops gracefully.
+	outputCtx    context.Context
+	cancelOutput context.CancelFunc
+
+	mu     sync.Mutex
+	stage  CancelStage
+	timer  *time.Timer
+	exited bool
+}
+
+// newProcessCanceler returns a processCanceler for cmd, and sets it as the
+// Cancel function of cmd.
+func newProcessCanceler(ctx context.Context, cmd *exec.Cmd, gracePeriod time.Duration) *processCanceler {
+	c := &processCanceler{
+		cmd:         cmd,
+		gracePeriod: gracePeriod,
+	}
+	c.outputCtx, c.cancelOutput = context.WithCancel(context.WithoutCancel(ctx))
+	cmd.Cancel = c.cancel
+	return c
+}
+
+func (c *processCanceler) cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.exited {
+		return nil
+	}
+
+	if c.gracePeriod <= 0 {
+		return c.kill()
+	}
+
+	if err := interruptProcess(c.cmd); err != nil {
+		return c.kill()
+	}
+	c.stage = CancelStageInterrupt
+
+	c.timer = time.AfterFunc(c.gracePeriod, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.exited {
+			_ = c.kill()
+		}
+	})
+	return nil
+}
+
+// kill kills the process. The caller must hold c.mu.
+func (c *processCanceler) kill() error {
+	c.stage = CancelStageKill
+	c.cancelOutput()
+	return killProcess(c.cmd)
+}
+
+// done must be called once Wait returned, or if the process was never
+// started, and returns the stage which ended it. Cancelling has no effect
+// afterwards.
+func (c *processCanceler) done() CancelStage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exited = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancelOutput()
+	return c.stage
+}
@@ -16,6 +16,9 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+
+	tfjson "github.com/hashicorp/terraform-json"
 
 	"github.com/opentofu/tofu-exec/internal/version"
 )
@@ -34,9 +37,13 @@ const (
 	workspaceEnvVar          = "TF_WORKSPACE"
 	disablePluginTLSEnvVar   = "TF_DISABLE_PLUGIN_TLS"
 	skipProviderVerifyEnvVar = "TF_SKIP_PROVIDER_VERIFY"
+	encryptionEnvVar         = "TF_ENCRYPTION"
+	traceParentEnvVar        = "TRACEPARENT"
+	traceStateEnvVar         = "TRACESTATE"
 
 	varEnvVarPrefix    = "TF_VAR_"
 	cliArgEnvVarPrefix = "TF_CLI_ARGS_"
+	otelEnvVarPrefix   = "OTEL_"
 )
 
 var prohibitedEnvVars = []string{
@@ -52,6 +59,7 @@ var prohibitedEnvVars = []string{
 	workspaceEnvVar,
 	disablePluginTLSEnvVar,
 	skipProviderVerifyEnvVar,
+	encryptionEnvVar,
 }
 
 var prohibitedEnvVarPrefixes = []string{
@@ -112,6 +120,8 @@ func envSlice(environ map[string]string) []string {
 	return env
 }
 
+// buildEnv returns the environment for a command. The caller must hold tf.mu
+// for reading.
 func (tf *Tofu) buildEnv(mergeEnv map[string]string) []string {
 	// set OpenTofu level env, if env is nil, fall back to os.Environ
 	var env map[string]string
@@ -171,20 +181,195 @@ func (tf *Tofu) buildEnv(mergeEnv map[string]string) []string {
 		env[skipProviderVerifyEnvVar] = "1"
 	}
 
+	if tf.encryption != "" {
+		env[encryptionEnvVar] = tf.encryption
+	}
+
 	return envSlice(env)
 }
 
+// buildTofuCmd returns the command running OpenTofu with the given
+// arguments. If the command is refused, for example in read-only mode, the
+// error is returned by runTofuCmd.
 func (tf *Tofu) buildTofuCmd(ctx context.Context, mergeEnv map[string]string, args ...string) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, tf.execPath, args...)
+	tf.mu.RLock()
+	readOnly := tf.readOnly
+	tf.mu.RUnlock()
 
-	cmd.Env = tf.buildEnv(mergeEnv)
-	cmd.Dir = tf.workingDir
+	if readOnly {
+		readOnlyArgs, err := readOnlyArgs(args)
+		if err != nil {
+			cmd := exec.CommandContext(ctx, tf.execPath, args...)
+			cmd.Dir = tf.workingDir
+			cmd.Err = err
+			return cmd
+		}
+		args = readOnlyArgs
+	}
+
+	tf.mu.RLock()
+	env := tf.buildEnv(mergeEnv)
+	tf.mu.RUnlock()
 
-	tf.logger.Printf("[INFO] running Tofu command: %s", cmd.String())
+	cmd := exec.CommandContext(ctx, tf.execPath, args...)
+	cmd.Env = env
+	cmd.Dir = tf.workingDir
 
 	return cmd
 }
 
+// runTofuCmd runs a command built with buildTofuCmd in its span and through
+// the hooks, logging its start and finish. A refused command is returned
+// before any of these.
+func (tf *Tofu) runTofuCmd(ctx context.Context, cmd *exec.Cmd) error {
+	var roErr *ReadOnlyError
+	if errors.As(cmd.Err, &roErr) {
+		return cmd.Err
+	}
+
+	tf.mu.RLock()
+	hooks := tf.hooks
+	tracer := tf.tracer
+	tf.mu.RUnlock()
+
+	run := func(ctx context.Context, c *Command) error {
+		// secrets must not end up in the log, errors or captured output
+		redactor := tf.redactor(c.Cmd)
+
+		runLog := tf.startRunLog(ctx, c.RunID, c.Cmd, redactor)
+		err := tf.runCmd(ctx, c.Cmd, c.RunID, redactor)
+		runLog.finish(ctx, c.Cmd, err)
+
+		return err
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		run = hooks[i](run)
+	}
+
+	runID := newRunID()
+	cs := startCommandSpan(ctx, tracer, cmd.Dir, cmd.Args[1:])
+	if cs != nil {
+		runID = cs.runID
+		ctx = cs.context(ctx)
+		cs.span.SetAttributes(TraceAttribute{Key: "tofu.workspace", Value: currentWorkspace(cmd.Dir, cmd.Env)})
+		cmd.Env = traceEnv(cmd.Env, cs.span)
+	}
+	err := run(ctx, &Command{RunID: runID, Cmd: cmd})
+	cs.end(cmd, err)
+
+	return err
+}
+
+// runCmd runs the process of a command, see runTofuCmd.
+func (tf *Tofu) runCmd(ctx context.Context, cmd *exec.Cmd, runID string, redactor *redactor) error {
+	var errBuf strings.Builder
+
+	tf.mu.RLock()
+	gracePeriod := tf.cancelGracePeriod
+	tf.mu.RUnlock()
+
+	setSysProcAttr(cmd, gracePeriod)
+
+	// check for early cancellation
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// Read stdout / stderr logs from pipe instead of setting cmd.Stdout and
+	// cmd.Stderr because it can cause hanging when killing the command
+	// https://github.com/golang/go/issues/23019
+	stdout, stderr := tf.outputWriters(ctx)
+	recorder := newRunRecorder(ctx)
+	recordStdout, recordStderr := recorder.writers()
+	stdoutWriter := mergeWriters(cmd.Stdout, stdout, recordStdout)
+	stderrWriter := mergeWriters(cmd.Stderr, stderr, &errBuf, recordStderr)
+
+	// collect diagnostics from the machine-readable UI so that they can be
+	// returned as part of the error
+	var diags diagnosticsWriter
+	if isJSONCmd(cmd) {
+		stdoutWriter = mergeWriters(stdoutWriter, &diags)
+	}
+
+	cmd.Stderr = nil
+	cmd.Stdout = nil
+
+	canceler := newProcessCanceler(ctx, cmd, gracePeriod)
+	defer canceler.done()
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	recorder.started()
+	err = cmd.Start()
+	if err != nil {
+		if ctx.Err() != nil {
+			return cmdErr{
+				err:    err,
+				ctxErr: ctx.Err(),
+			}
+		}
+		return err
+	}
+
+	// once started, the process is waited for even if ctx is already done,
+	// so that the canceler can stop it and it is reaped
+
+	var errStdout, errStderr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errStdout = writeOutput(canceler.outputCtx, stdoutPipe, stdoutWriter)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errStderr = writeOutput(canceler.outputCtx, stderrPipe, stderrWriter)
+	}()
+
+	// Reads from pipes must be completed before calling cmd.Wait(). Otherwise
+	// can cause a race condition
+	wg.Wait()
+
+	err = cmd.Wait()
+	stage := canceler.done()
+	recorder.finish(cmd, runID, redactor)
+	if err != nil {
+		err = newExitError(err, cmd.Args[1:], redactor.redact(errBuf.String()), redactor.redactDiagnostics(diags.diagnostics))
+	}
+	if ctx.Err() != nil {
+		return cmdErr{
+			err:    err,
+			ctxErr: ctx.Err(),
+			stage:  stage,
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Return error if there was an issue reading the std out/err
+	if errStdout != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w\n%s", errStdout, redactor.redact(errBuf.String()))
+	}
+	if errStderr != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w\n%s", errStderr, redactor.redact(errBuf.String()))
+	}
+
+	return nil
+}
+
 func (tf *Tofu) runTofuCmdJSON(ctx context.Context, cmd *exec.Cmd, v interface{}) error {
 	var outbuf = bytes.Buffer{}
 	cmd.Stdout = mergeWriters(cmd.Stdout, &outbuf)
@@ -199,6 +384,18 @@ func (tf *Tofu) runTofuCmdJSON(ctx context.Context, cmd *exec.Cmd, v interface{}
 	return dec.Decode(v)
 }
 
+// checkExcludes asserts that the -exclude flag is supported by the OpenTofu
+// version and not combined with -target.
+func (tf *Tofu) checkExcludes(ctx context.Context, targets []string, excludes []string) error {
+	if len(excludes) == 0 {
+		return nil
+	}
+	if len(targets) > 0 {
+		return fmt.Errorf("you cannot use -target and -exclude at the same time")
+	}
+	return tf.compatible(ctx, tofu1_9_0, nil)
+}
+
 // mergeUserAgent does some minor deduplication to ensure we aren't
 // just using the same append string over and over.
 func mergeUserAgent(uas ...string) string {
@@ -235,6 +432,55 @@ func mergeWriters(writers ...io.Writer) io.Writer {
 	return io.MultiWriter(compact...)
 }
 
+// isJSONCmd reports whether the command was built with the -json flag.
+func isJSONCmd(cmd *exec.Cmd) bool {
+	for _, arg := range cmd.Args[1:] {
+		if arg == "-json" {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnosticsWriter collects the diagnostics emitted on the machine-readable
+// UI of a command run with the -json flag.
+type diagnosticsWriter struct {
+	diagnostics []tfjson.Diagnostic
+}
+
+func (w *diagnosticsWriter) Write(p []byte) (int, error) {
+	// avoid decoding lines which cannot be diagnostics, such as large
+	// state or plan documents
+	if !bytes.Contains(p, []byte(`"diagnostic"`)) {
+		return len(p), nil
+	}
+
+	ev, err := ParseEvent(bytes.TrimSpace(p))
+	if err != nil {
+		return len(p), nil
+	}
+	if diag, ok := ev.(*DiagnosticEvent); ok {
+		w.diagnostics = append(w.diagnostics, diag.Diagnostic)
+	}
+
+	return len(p), nil
+}
+
+// newExitError wraps an error returned from exec.Cmd.Wait with the stderr
+// output of the command and with any diagnostics emitted on stdout, and
+// classifies well known failures of the command run with args.
+func newExitError(err error, args []string, stderr string, diags []tfjson.Diagnostic) error {
+	err = fmt.Errorf("%w\n%s", err, stderr)
+	if len(diags) > 0 {
+		err = &DiagnosticsError{
+			Diagnostics: diags,
+			err:         err,
+		}
+	}
+
+	return classifyExitError(err, args, stderr, diags)
+}
+
 func writeOutput(ctx context.Context, r io.ReadCloser, w io.Writer) error {
 	// ReadBytes will block until bytes are read, which can cause a delay in
 	// returning even if the command's context has been canceled. Use a separate
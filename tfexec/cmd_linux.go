@@ -6,97 +6,51 @@
 package tfexec
 
 import (
-	"context"
-	"fmt"
+	"errors"
+	"os"
 	"os/exec"
-	"strings"
-	"sync"
 	"syscall"
+	"time"
 )
 
-func (tf *Tofu) runTofuCmd(ctx context.Context, cmd *exec.Cmd) error {
-	var errBuf strings.Builder
-
+// setSysProcAttr sets the platform specific attributes of the process of a
+// command, see SetCancelGracePeriod.
+func setSysProcAttr(cmd *exec.Cmd, gracePeriod time.Duration) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		// kill children if parent is dead
 		Pdeathsig: syscall.SIGKILL,
-		// set process group ID
-		Setpgid: true,
-	}
-
-	// check for early cancellation
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
-	// Read stdout / stderr logs from pipe instead of setting cmd.Stdout and
-	// cmd.Stderr because it can cause hanging when killing the command
-	// https://github.com/golang/go/issues/23019
-	stdoutWriter := mergeWriters(cmd.Stdout, tf.stdout)
-	stderrWriter := mergeWriters(tf.stderr, &errBuf)
-
-	cmd.Stderr = nil
-	cmd.Stdout = nil
-
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
+		// with a grace period, set the process group ID so that the
+		// cancellation signals reach the provider plugins too
+		Setpgid: gracePeriod > 0,
 	}
+}
 
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		return err
-	}
-
-	err = cmd.Start()
-	if ctx.Err() != nil {
-		return cmdErr{
-			err:    err,
-			ctxErr: ctx.Err(),
-		}
-	}
-	if err != nil {
-		return err
-	}
-
-	var errStdout, errStderr error
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		errStdout = writeOutput(ctx, stdoutPipe, stdoutWriter)
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		errStderr = writeOutput(ctx, stderrPipe, stderrWriter)
-	}()
+func interruptProcess(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGINT)
+}
 
-	// Reads from pipes must be completed before calling cmd.Wait(). Otherwise
-	// can cause a race condition
-	wg.Wait()
+func killProcess(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGKILL)
+}
 
-	err = cmd.Wait()
-	if ctx.Err() != nil {
-		return cmdErr{
-			err:    err,
-			ctxErr: ctx.Err(),
-		}
+// signalProcessGroup signals the process group of the command if it was
+// started in its own, and only the process otherwise.
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		return cmd.Process.Signal(sig)
 	}
-	if err != nil {
-		return fmt.Errorf("%w\n%s", err, errBuf.String())
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
 	}
+	return err
+}
 
-	// Return error if there was an issue reading the std out/err
-	if errStdout != nil && ctx.Err() != nil {
-		return fmt.Errorf("%w\n%s", errStdout, errBuf.String())
+func maxRSS(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
 	}
-	if errStderr != nil && ctx.Err() != nil {
-		return fmt.Errorf("%w\n%s", errStderr, errBuf.String())
-	}
-
-	return nil
+	// ru_maxrss is in kilobytes on Linux
+	return rusage.Maxrss * 1024
 }
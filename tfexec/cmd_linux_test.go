@@ -8,8 +8,16 @@ package tfexec
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"os/exec"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -39,3 +47,610 @@ func Test_runTofuCmd_linux(t *testing.T) {
 		t.Fatal("canceling context should not lead to logging an error")
 	}
 }
+
+func Test_runTofuCmd_diagnostics(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+
+	script := `echo '{"@level":"info","@message":"OpenTofu 1.10.5","type":"version","tofu":"1.10.5","ui":"1.2"}'
+echo '{"@level":"warn","@message":"Warning: Deprecated","type":"diagnostic","diagnostic":{"severity":"warning","summary":"Deprecated"}}'
+echo '{"@level":"error","@message":"Error: No value for required variable","type":"diagnostic","diagnostic":{"severity":"error","summary":"No value for required variable","detail":"The root module input variable \"foo\" is not set."}}'
+exit 1`
+
+	ctx := context.Background()
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", script, "sh", "-json")
+	err := tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	var diagErr *DiagnosticsError
+	if !errors.As(err, &diagErr) {
+		t.Fatalf("expected DiagnosticsError, got %T %s", err, err)
+	}
+	if len(diagErr.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(diagErr.Diagnostics))
+	}
+	if diagErr.Diagnostics[1].Summary != "No value for required variable" {
+		t.Fatalf("unexpected diagnostic summary %q", diagErr.Diagnostics[1].Summary)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected exec.ExitError, got %T %s", err, err)
+	}
+
+	if !strings.Contains(err.Error(), "Error: No value for required variable") {
+		t.Fatalf("expected error message to contain diagnostic, got %q", err.Error())
+	}
+	if strings.Contains(err.Error(), "Deprecated") {
+		t.Fatalf("expected error message to omit warnings, got %q", err.Error())
+	}
+}
+
+func Test_runTofuCmd_diagnosticsWithoutJSON(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+
+	script := `echo '{"@level":"error","@message":"Error: boom","type":"diagnostic","diagnostic":{"severity":"error","summary":"boom"}}'
+exit 1`
+
+	ctx := context.Background()
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", script)
+	err := tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	var diagErr *DiagnosticsError
+	if errors.As(err, &diagErr) {
+		t.Fatalf("expected plain error for command without -json, got %T %s", err, err)
+	}
+}
+
+func Test_runTofuCmd_withOutput(t *testing.T) {
+	var shared bytes.Buffer
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+	tf.SetStdout(&shared)
+
+	var stdout, stderr bytes.Buffer
+	ctx := WithOutput(context.Background(), &stdout, &stderr)
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", "echo out; echo err >&2")
+	if err := tf.runTofuCmd(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != "out\n" {
+		t.Fatalf("expected run stdout %q, got %q", "out\n", stdout.String())
+	}
+	if stderr.String() != "err\n" {
+		t.Fatalf("expected run stderr %q, got %q", "err\n", stderr.String())
+	}
+
+	ctx = context.Background()
+	cmd = tf.buildTofuCmd(ctx, nil, "-c", "echo again")
+	if err := tf.runTofuCmd(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != "out\n" {
+		t.Fatalf("expected run stdout to be unaffected by later commands, got %q", stdout.String())
+	}
+	if shared.String() != "out\nagain\n" {
+		t.Fatalf("expected shared stdout %q, got %q", "out\nagain\n", shared.String())
+	}
+}
+
+func Test_runTofuCmd_concurrent(t *testing.T) {
+	// Checks that a Tofu instance can be shared across goroutines, run with
+	// go test -race -run Test_runTofuCmd_concurrent ./tfexec
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "echo",
+	}
+
+	const n = 10
+	outputs := make([]bytes.Buffer, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithOutput(context.Background(), &outputs[i], nil)
+			cmd := tf.buildTofuCmd(ctx, nil, fmt.Sprintf("run-%d", i))
+			errs[i] = tf.runTofuCmd(ctx, cmd)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_ = tf.SetEnv(map[string]string{"FOO": fmt.Sprint(i)})
+			_ = tf.SetLogPath(fmt.Sprintf("/tmp/log-%d", i))
+			_ = tf.SetAppendUserAgent(fmt.Sprintf("ua-%d", i))
+			tf.SetStderr(io.Discard)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("run %d: %s", i, errs[i])
+		}
+		expected := fmt.Sprintf("run-%d\n", i)
+		if outputs[i].String() != expected {
+			t.Fatalf("run %d: expected output %q, got %q", i, expected, outputs[i].String())
+		}
+	}
+}
+
+func Test_runTofuCmd_cancel(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		gracePeriod time.Duration
+		script      string
+		stage       CancelStage
+		output      string
+	}{
+		{
+			name:   "immediate kill",
+			script: `trap 'echo interrupted; exit 3' INT; echo started; while true; do sleep 0.1; done`,
+			stage:  CancelStageKill,
+			output: "started\n",
+		},
+		{
+			name:        "interrupt",
+			gracePeriod: 10 * time.Second,
+			script:      `trap 'echo interrupted; exit 3' INT; echo started; while true; do sleep 0.1; done`,
+			stage:       CancelStageInterrupt,
+			output:      "started\ninterrupted\n",
+		},
+		{
+			name:        "kill after grace period",
+			gracePeriod: 500 * time.Millisecond,
+			script:      `trap '' INT; echo started; while true; do sleep 0.1; done`,
+			stage:       CancelStageKill,
+			output:      "started\n",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tf := &Tofu{
+				logger:   log.New(io.Discard, "", 0),
+				execPath: "sh",
+			}
+			tf.SetCancelGracePeriod(c.gracePeriod)
+
+			var stdout bytes.Buffer
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd := tf.buildTofuCmd(ctx, nil, "-c", c.script)
+			cmd.Stdout = &stdout
+
+			time.AfterFunc(300*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := tf.runTofuCmd(ctx, cmd)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if stage := CancelStageOf(err); stage != c.stage {
+				t.Fatalf("expected cancel stage %q, got %q", c.stage, stage)
+			}
+			if stdout.String() != c.output {
+				t.Fatalf("expected output %q, got %q", c.output, stdout.String())
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("expected command to return promptly, took %s", elapsed)
+			}
+			if cmd.SysProcAttr.Pdeathsig != syscall.SIGKILL {
+				t.Fatalf("expected SIGKILL on parent death, got %s", cmd.SysProcAttr.Pdeathsig)
+			}
+			if cmd.SysProcAttr.Setpgid != (c.gracePeriod > 0) {
+				t.Fatalf("expected own process group only with a grace period, got %t", cmd.SysProcAttr.Setpgid)
+			}
+		})
+	}
+}
+
+func Test_runTofuCmd_withResult(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var result RunResult
+		ctx := WithResult(context.Background(), &result)
+
+		cmd := tf.buildTofuCmd(ctx, nil, "-c", "echo out; echo 'Warning: deprecated' >&2; sleep 0.1")
+		err := tf.runTofuCmd(ctx, cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.ExitCode != 0 {
+			t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+		}
+		if strings.Join(result.Args, " ") != "-c echo out; echo 'Warning: deprecated' >&2; sleep 0.1" {
+			t.Fatalf("unexpected args %q", result.Args)
+		}
+		if string(result.Stdout) != "out\n" {
+			t.Fatalf("unexpected stdout %q", result.Stdout)
+		}
+		if string(result.Stderr) != "Warning: deprecated\n" {
+			t.Fatalf("unexpected stderr %q", result.Stderr)
+		}
+		if result.Duration < 100*time.Millisecond {
+			t.Fatalf("expected duration of at least 100ms, got %s", result.Duration)
+		}
+		if result.MaxRSS <= 0 {
+			t.Fatalf("expected peak memory usage, got %d", result.MaxRSS)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var result RunResult
+		ctx := WithResult(context.Background(), &result)
+
+		cmd := tf.buildTofuCmd(ctx, nil, "-c", "echo failed >&2; exit 3")
+		err := tf.runTofuCmd(ctx, cmd)
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+
+		if result.ExitCode != 3 {
+			t.Fatalf("expected exit code 3, got %d", result.ExitCode)
+		}
+		if string(result.Stderr) != "failed\n" {
+			t.Fatalf("unexpected stderr %q", result.Stderr)
+		}
+	})
+
+	t.Run("side command", func(t *testing.T) {
+		var result RunResult
+		ctx := WithResult(context.Background(), &result)
+
+		cmd := tf.buildTofuCmd(ctx, nil, "-c", "echo main")
+		err := tf.runTofuCmd(ctx, cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sideCtx := withoutResult(ctx)
+		cmd = tf.buildTofuCmd(sideCtx, nil, "-c", "echo side")
+		err = tf.runTofuCmd(sideCtx, cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(result.Stdout) != "main\n" {
+			t.Fatalf("expected result of the main command, got stdout %q", result.Stdout)
+		}
+	})
+}
+
+func Test_runTofuCmd_redaction(t *testing.T) {
+	var logs bytes.Buffer
+	tf := &Tofu{
+		logger:   log.New(&logs, "", 0),
+		execPath: "sh",
+	}
+	err := tf.SetEnv(map[string]string{
+		"TF_TOKEN_app_example_com": "token-from-env",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf.AddSecrets("registered-secret")
+
+	script := `echo "Running with $2 $4 $6"
+echo "Error: invalid credentials $6 $TF_TOKEN_app_example_com registered-secret" >&2
+exit 1`
+
+	var result RunResult
+	ctx := WithResult(context.Background(), &result)
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", script, "sh", "-var", "count=1000", "-var", "enabled=true", "-backend-config=token=backend-secret", "registered-secret")
+	err = tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	for _, secret := range []string{"backend-secret", "token-from-env", "registered-secret"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("secret %q logged: %s", secret, logs.String())
+		}
+		if strings.Contains(err.Error(), secret) {
+			t.Fatalf("secret %q in error: %s", secret, err)
+		}
+		if strings.Contains(strings.Join(result.Args, " "), secret) || strings.Contains(string(result.Stdout), secret) || strings.Contains(string(result.Stderr), secret) {
+			t.Fatalf("secret %q in result: %v %s %s", secret, result.Args, result.Stdout, result.Stderr)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "-var count=[REDACTED] -var enabled=[REDACTED] -backend-config=token=[REDACTED] [REDACTED]") {
+		t.Fatalf("expected redacted command in log, got: %s", logs.String())
+	}
+
+	// values of -var flags are not secrets, so they are left intact in the
+	// output
+	if string(result.Stdout) != "Running with count=1000 enabled=true [REDACTED]\n" {
+		t.Fatalf("expected -var values in output, got %q", result.Stdout)
+	}
+}
+
+func Test_runTofuCmd_sensitiveVarRedaction(t *testing.T) {
+	var logs bytes.Buffer
+	tf := &Tofu{
+		logger:   log.New(&logs, "", 0),
+		execPath: "sh",
+	}
+
+	mergeEnv := map[string]string{}
+	err := addTypedVarEnv(mergeEnv, []*VarValueOption{
+		SensitiveVar("db_pass", "p4ssw0rd-value"),
+		SensitiveVar("db", map[string]string{"user": "admin", "pass": "nested-pass"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := `echo '{"type":"diagnostic","diagnostic":{"severity":"error","summary":"Invalid password '"$TF_VAR_db_pass"'","detail":"Invalid credentials nested-pass"}}'
+echo "Error: invalid credentials $TF_VAR_db_pass $TF_VAR_db" >&2
+exit 1`
+
+	var result RunResult
+	ctx := WithResult(context.Background(), &result)
+	cmd := tf.buildTofuCmd(ctx, mergeEnv, "-c", script, "sh", "-json")
+	err = tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	var diagErr *DiagnosticsError
+	if !errors.As(err, &diagErr) || len(diagErr.Diagnostics) != 1 {
+		t.Fatalf("expected DiagnosticsError with 1 diagnostic, got %T %s", err, err)
+	}
+	if diagErr.Diagnostics[0].Summary != "Invalid password [REDACTED]" {
+		t.Fatalf("expected redacted diagnostic, got %q", diagErr.Diagnostics[0].Summary)
+	}
+
+	for _, secret := range []string{"p4ssw0rd-value", "nested-pass"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("secret %q logged: %s", secret, logs.String())
+		}
+		if strings.Contains(err.Error(), secret) {
+			t.Fatalf("secret %q in error: %s", secret, err)
+		}
+		if strings.Contains(diagErr.Diagnostics[0].Detail, secret) {
+			t.Fatalf("secret %q in diagnostic: %s", secret, diagErr.Diagnostics[0].Detail)
+		}
+		if strings.Contains(string(result.Stdout), secret) || strings.Contains(string(result.Stderr), secret) {
+			t.Fatalf("secret %q in result: %s %s", secret, result.Stdout, result.Stderr)
+		}
+	}
+}
+
+func Test_runTofuCmd_slog(t *testing.T) {
+	var logs bytes.Buffer
+	td := t.TempDir()
+	tf := &Tofu{
+		execPath:   "sh",
+		workingDir: td,
+	}
+	tf.SetSlogLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	var result RunResult
+	ctx := WithResult(context.Background(), &result)
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", "exit 3", "sh", "-var", "password=hunter22")
+	err := tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	type record struct {
+		Msg        string  `json:"msg"`
+		RunID      string  `json:"run_id"`
+		WorkingDir string  `json:"working_dir"`
+		Workspace  string  `json:"workspace"`
+		Command    string  `json:"command"`
+		Duration   *int64  `json:"duration"`
+		ExitCode   *int    `json:"exit_code"`
+		Error      *string `json:"error"`
+	}
+	var records []record
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected start and finish records, got %d", len(records))
+	}
+	start, finish := records[0], records[1]
+	if start.Msg != "running Tofu command" || finish.Msg != "finished Tofu command" {
+		t.Fatalf("unexpected messages %q and %q", start.Msg, finish.Msg)
+	}
+	if start.RunID == "" || start.RunID != finish.RunID || start.RunID != result.RunID {
+		t.Fatalf("expected the same run ID, got %q, %q and %q", start.RunID, finish.RunID, result.RunID)
+	}
+	if start.WorkingDir != td || start.Workspace != "default" {
+		t.Fatalf("unexpected working dir %q or workspace %q", start.WorkingDir, start.Workspace)
+	}
+	if strings.Contains(start.Command, "hunter22") {
+		t.Fatalf("secret logged: %s", start.Command)
+	}
+	if start.ExitCode != nil || start.Duration != nil {
+		t.Fatal("expected start record without exit code and duration")
+	}
+	if finish.ExitCode == nil || *finish.ExitCode != 3 || finish.Duration == nil || finish.Error == nil {
+		t.Fatalf("expected finish record with exit code, duration and error, got %+v", finish)
+	}
+}
+
+func Test_runTofuCmd_hooks(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+
+	var calls []string
+	var observed bytes.Buffer
+	var exitCode int
+	var hookErr error
+	tf.AddHook(
+		func(next RunFunc) RunFunc {
+			return func(ctx context.Context, c *Command) error {
+				calls = append(calls, "outer before")
+				err := next(ctx, c)
+				calls = append(calls, "outer after")
+				exitCode = c.Cmd.ProcessState.ExitCode()
+				hookErr = err
+				return fmt.Errorf("wrapped: %w", err)
+			}
+		},
+		func(next RunFunc) RunFunc {
+			return func(ctx context.Context, c *Command) error {
+				calls = append(calls, "inner before")
+				c.Cmd.Env = append(c.Cmd.Env, "INJECTED=credentials")
+				c.Cmd.Args = append(c.Cmd.Args, "from-hook")
+				c.AddStdout(&observed)
+				err := next(ctx, c)
+				calls = append(calls, "inner after")
+				return err
+			}
+		},
+	)
+
+	var stdout bytes.Buffer
+	ctx := context.Background()
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", `echo "$INJECTED $1"; exit 4`, "sh")
+	cmd.Stdout = &stdout
+	err := tf.runTofuCmd(ctx, cmd)
+
+	if err == nil || !strings.HasPrefix(err.Error(), "wrapped: ") {
+		t.Fatalf("expected wrapped error, got %v", err)
+	}
+	if hookErr == nil {
+		t.Fatal("expected hook to see the error")
+	}
+	if exitCode != 4 {
+		t.Fatalf("expected exit code 4, got %d", exitCode)
+	}
+	if stdout.String() != "credentials from-hook\n" || observed.String() != stdout.String() {
+		t.Fatalf("unexpected output %q and observed output %q", stdout.String(), observed.String())
+	}
+	expectedCalls := []string{"outer before", "inner before", "inner after", "outer after"}
+	if strings.Join(calls, ",") != strings.Join(expectedCalls, ",") {
+		t.Fatalf("expected calls %q, got %q", expectedCalls, calls)
+	}
+}
+
+func Test_runTofuCmd_hookPreventsRun(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+
+	errLocked := errors.New("lock held elsewhere")
+	tf.AddHook(func(next RunFunc) RunFunc {
+		return func(ctx context.Context, c *Command) error {
+			return errLocked
+		}
+	})
+
+	ctx := context.Background()
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", "exit 0")
+	err := tf.runTofuCmd(ctx, cmd)
+	if !errors.Is(err, errLocked) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if cmd.ProcessState != nil {
+		t.Fatal("expected command not to be started")
+	}
+}
+
+func Test_runTofuCmd_tracing(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_SERVICE_NAME", "from-environ")
+
+	tracer := &memoryTracer{}
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "sh",
+	}
+	err := tf.SetEnv(map[string]string{
+		"OTEL_SERVICE_NAME": "deployer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf.SetTracer(tracer)
+
+	var hookSpan any
+	tf.AddHook(func(next RunFunc) RunFunc {
+		return func(ctx context.Context, c *Command) error {
+			hookSpan = ctx.Value(memorySpanContextKey{})
+			return next(ctx, c)
+		}
+	})
+
+	ctx, parent := tracer.Start(context.Background(), "deploy")
+
+	// commands which are built but never run start no span
+	_ = tf.buildTofuCmd(ctx, nil, "-c", "exit 0")
+
+	var stdout bytes.Buffer
+	cmd := tf.buildTofuCmd(ctx, nil, "-c", `echo "$TRACEPARENT $OTEL_TRACES_EXPORTER $OTEL_SERVICE_NAME"; exit 1`, "plan")
+	cmd.Stdout = &stdout
+	err = tf.runTofuCmd(ctx, cmd)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	parent.End()
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[1]
+
+	if span.name != "tofu" {
+		t.Fatalf("unexpected span name %q", span.name)
+	}
+	if span.parent != parent.SpanContext() {
+		t.Fatal("expected command span to be a child of the span in the context")
+	}
+	if !span.ended || len(span.errs) != 1 || span.attrs["tofu.exit_code"] != 1 {
+		t.Fatalf("expected ended span with error and exit code, got %+v", span)
+	}
+	if span.attrs["tofu.run_id"] == "" || span.attrs["tofu.workspace"] != "default" {
+		t.Fatalf("expected run ID and workspace attributes, got %+v", span.attrs)
+	}
+	if hookSpan != span {
+		t.Fatal("expected hooks to run in the context of the command span")
+	}
+
+	fields := strings.Fields(stdout.String())
+	if len(fields) != 3 {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+	propagated, err := ParseTraceParent(fields[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if propagated != span.SpanContext() {
+		t.Fatalf("expected TRACEPARENT %s, got %s", span.SpanContext().TraceParent(), fields[0])
+	}
+	if fields[1] != "otlp" || fields[2] != "deployer" {
+		t.Fatalf("expected OTEL_* variables to be passed through unless overridden, got %q", stdout.String())
+	}
+}
@@ -14,9 +14,10 @@ import (
 )
 
 type destroyConfig struct {
-	backup string
-	dir    string
-	lock   bool
+	backup   string
+	dir      string
+	excludes []string
+	lock     bool
 
 	// LockTimeout must be a string with time unit, e.g. '10s'
 	lockTimeout  string
@@ -26,6 +27,7 @@ type destroyConfig struct {
 	state        string
 	stateOut     string
 	targets      []string
+	typedVars    []*VarValueOption
 
 	// Vars: each var must be supplied as a single string, e.g. 'foo=bar'
 	vars     []string
@@ -60,6 +62,10 @@ func (opt *TargetOption) configureDestroy(conf *destroyConfig) {
 	conf.targets = append(conf.targets, opt.target)
 }
 
+func (opt *ExcludeOption) configureDestroy(conf *destroyConfig) {
+	conf.excludes = append(conf.excludes, opt.exclude)
+}
+
 func (opt *LockTimeoutOption) configureDestroy(conf *destroyConfig) {
 	conf.lockTimeout = opt.timeout
 }
@@ -84,6 +90,10 @@ func (opt *RefreshOption) configureDestroy(conf *destroyConfig) {
 	conf.refresh = opt.refresh
 }
 
+func (opt *VarValueOption) configureDestroy(conf *destroyConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
 func (opt *VarOption) configureDestroy(conf *destroyConfig) {
 	conf.vars = append(conf.vars, opt.assignment)
 }
@@ -106,13 +116,26 @@ func (tf *Tofu) Destroy(ctx context.Context, opts ...DestroyOption) error {
 // JSON being written to the supplied `io.Writer`. DestroyJSON is likely to be
 // removed in a future major version in favour of Destroy returning JSON by default.
 func (tf *Tofu) DestroyJSON(ctx context.Context, w io.Writer, opts ...DestroyOption) error {
-	tf.SetStdout(w)
+	cmd, err := tf.destroyJSONCmd(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = mergeWriters(cmd.Stdout, w)
 
+	return tf.runTofuCmd(ctx, cmd)
+}
+
+// DestroyEvents represents the tofu destroy subcommand with the `-json` flag.
+// The handler is called with every machine-readable UI message as it is
+// emitted, see Event for the available message types.
+func (tf *Tofu) DestroyEvents(ctx context.Context, handler EventHandler, opts ...DestroyOption) error {
 	cmd, err := tf.destroyJSONCmd(ctx, opts...)
 	if err != nil {
 		return err
 	}
 
+	cmd.Stdout = mergeWriters(cmd.Stdout, newEventWriter(handler))
+
 	return tf.runTofuCmd(ctx, cmd)
 }
 
@@ -172,22 +195,37 @@ func (tf *Tofu) buildDestroyArgs(c destroyConfig) []string {
 			args = append(args, "-target="+ta)
 		}
 	}
+	if c.excludes != nil {
+		for _, ex := range c.excludes {
+			args = append(args, "-exclude="+ex)
+		}
+	}
 	if c.vars != nil {
 		for _, v := range c.vars {
 			args = append(args, "-var", v)
 		}
 	}
+	args = append(args, typedVarArgs(c.typedVars)...)
 
 	return args
 }
 
 func (tf *Tofu) buildDestroyCmd(ctx context.Context, c destroyConfig, args []string) (*exec.Cmd, error) {
+	err := tf.checkExcludes(ctx, c.targets, c.excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	// optional positional argument
 	if c.dir != "" {
 		args = append(args, c.dir)
 	}
 
 	mergeEnv := map[string]string{}
+	err = addTypedVarEnv(mergeEnv, c.typedVars)
+	if err != nil {
+		return nil, err
+	}
 	if c.reattachInfo != nil {
 		reattachStr, err := c.reattachInfo.marshalString()
 		if err != nil {
@@ -67,6 +67,32 @@ func TestDestroyCmd(t *testing.T) {
 			"destroydir",
 		}, nil, destroyCmd)
 	})
+	t.Run("exclude", func(t *testing.T) {
+		destroyCmd, err := tf.destroyCmd(context.Background(), Exclude("null_resource.foo"), Exclude("module.bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"destroy",
+			"-no-color",
+			"-auto-approve",
+			"-input=false",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"-parallelism=10",
+			"-refresh=true",
+			"-exclude=null_resource.foo",
+			"-exclude=module.bar",
+		}, nil, destroyCmd)
+	})
+
+	t.Run("exclude with target", func(t *testing.T) {
+		_, err := tf.destroyCmd(context.Background(), Target("null_resource.foo"), Exclude("module.bar"))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
 }
 
 func TestDestroyJSONCmd(t *testing.T) {
@@ -0,0 +1,165 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+// DriftReport is the result of DetectDrift.
+type DriftReport struct {
+	// Resources lists the resources which were changed or deleted outside of
+	// OpenTofu, in plan order.
+	Resources []*DriftedResource
+
+	// RefreshOnlyPlan is the refresh-only plan the drift was detected with.
+	RefreshOnlyPlan *tfjson.Plan
+
+	// Plan is the normal plan used to determine whether the drift would be
+	// reverted.
+	Plan *tfjson.Plan
+}
+
+// HasDrift returns whether any resource drifted.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Resources) > 0
+}
+
+// DriftedResource describes a resource which was changed or deleted outside
+// of OpenTofu.
+type DriftedResource struct {
+	Address string
+
+	// Deleted is true if the remote object no longer exists.
+	Deleted bool
+
+	// Change is the drift as reported in the resource_drift of the
+	// refresh-only plan, with the state before the refresh as Before and the
+	// refreshed state as After.
+	Change *tfjson.ResourceChange
+
+	// ChangedAttributes lists the attributes changed outside of OpenTofu.
+	ChangedAttributes []planquery.AttributeChange
+
+	// PlannedAction is the action of the next normal plan on the resource,
+	// planquery.ActionNoOp if the resource is not part of the plan.
+	PlannedAction planquery.Action
+
+	// Reverted is true if the next normal plan would revert the drift,
+	// i.e. recreate a deleted resource, replace the resource, or update at
+	// least one of the drifted attributes.
+	Reverted bool
+}
+
+// DetectDrift detects changes made outside of OpenTofu by running a
+// refresh-only plan, followed by a normal plan to determine which of them
+// would be reverted by applying the configuration. Neither plan is saved
+// and the state is left unchanged.
+//
+// The options are passed to both plans. The Destroy, Out, RefreshOnly and
+// GenerateConfigOut options are not supported.
+func (tf *Tofu) DetectDrift(ctx context.Context, opts ...PlanOption) (*DriftReport, error) {
+	c := defaultPlanOptions
+
+	for _, o := range opts {
+		o.configurePlan(&c)
+	}
+
+	switch {
+	case c.destroy:
+		return nil, fmt.Errorf("the Destroy option is not supported for DetectDrift")
+	case c.out != "":
+		return nil, fmt.Errorf("the Out option is not supported for DetectDrift")
+	case c.refreshOnly:
+		return nil, fmt.Errorf("the RefreshOnly option is not supported for DetectDrift")
+	case c.generateConfigOut != "":
+		return nil, fmt.Errorf("the GenerateConfigOut option is not supported for DetectDrift")
+	}
+
+	refreshOnly, err := tf.PlanDetailed(ctx, append(opts, RefreshOnly(true))...)
+	if err != nil {
+		return nil, err
+	}
+	refreshOnly.Close()
+
+	report := &DriftReport{RefreshOnlyPlan: refreshOnly.Plan}
+	if len(refreshOnly.Plan.ResourceDrift) == 0 {
+		return report, nil
+	}
+
+	plan, err := tf.PlanDetailed(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	plan.Close()
+
+	report.Plan = plan.Plan
+	report.Resources = driftedResources(refreshOnly.Plan, plan.Plan)
+
+	return report, nil
+}
+
+func driftedResources(refreshOnly *tfjson.Plan, plan *tfjson.Plan) []*DriftedResource {
+	planned := map[string]*tfjson.ResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		if rc.DeposedKey == "" {
+			planned[rc.Address] = rc
+		}
+	}
+
+	drift := planquery.New(&tfjson.Plan{ResourceChanges: refreshOnly.ResourceDrift}).
+		Actions(planquery.ActionUpdate, planquery.ActionDelete)
+
+	var resources []*DriftedResource
+	for _, rc := range drift.ResourceChanges() {
+		dr := &DriftedResource{
+			Address:           rc.Address,
+			Deleted:           planquery.ActionOf(rc) == planquery.ActionDelete,
+			Change:            rc,
+			ChangedAttributes: planquery.ChangedAttributes(rc),
+			PlannedAction:     planquery.ActionNoOp,
+		}
+
+		if p, ok := planned[rc.Address]; ok {
+			dr.PlannedAction = planquery.ActionOf(p)
+			switch {
+			case dr.PlannedAction == planquery.ActionCreate, dr.PlannedAction.IsReplace():
+				dr.Reverted = true
+			case dr.PlannedAction == planquery.ActionUpdate:
+				dr.Reverted = attributesOverlap(dr.ChangedAttributes, planquery.ChangedAttributes(p))
+			}
+		}
+
+		resources = append(resources, dr)
+	}
+	return resources
+}
+
+// attributesOverlap returns whether any attribute of a is, contains or is
+// contained in any attribute of b.
+func attributesOverlap(a []planquery.AttributeChange, b []planquery.AttributeChange) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if attributePathContains(x.Path, y.Path) || attributePathContains(y.Path, x.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attributePathContains(parent string, path string) bool {
+	if parent == path {
+		return true
+	}
+	return strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[")
+}
@@ -0,0 +1,116 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+func TestDriftedResources(t *testing.T) {
+	var refreshOnly, plan tfjson.Plan
+	err := json.Unmarshal([]byte(`{
+		"format_version": "1.2",
+		"resource_drift": [
+			{"address": "aws_instance.tags", "change": {"actions": ["update"], "before": {"tags": {"Owner": "a"}, "size": 1}, "after": {"tags": {"Owner": "b"}, "size": 1}}},
+			{"address": "aws_instance.unrelated", "change": {"actions": ["update"], "before": {"size": 1, "ami": "a"}, "after": {"size": 2, "ami": "a"}}},
+			{"address": "aws_instance.deleted", "change": {"actions": ["delete"], "before": {"id": "i-1"}, "after": null}},
+			{"address": "aws_instance.accepted", "change": {"actions": ["update"], "before": {"size": 1}, "after": {"size": 2}}}
+		]
+	}`), &refreshOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal([]byte(`{
+		"format_version": "1.2",
+		"resource_changes": [
+			{"address": "aws_instance.tags", "change": {"actions": ["update"], "before": {"tags": {"Owner": "b"}}, "after": {"tags": {"Owner": "a"}}}},
+			{"address": "aws_instance.unrelated", "change": {"actions": ["update"], "before": {"size": 2, "ami": "a"}, "after": {"size": 2, "ami": "b"}}},
+			{"address": "aws_instance.deleted", "change": {"actions": ["create"], "before": null, "after": {"id": null}}},
+			{"address": "aws_instance.accepted", "change": {"actions": ["no-op"], "before": {"size": 2}, "after": {"size": 2}}}
+		]
+	}`), &plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		Address       string
+		Deleted       bool
+		PlannedAction planquery.Action
+		Reverted      bool
+		Paths         []string
+	}
+	var actual []result
+	for _, r := range driftedResources(&refreshOnly, &plan) {
+		res := result{
+			Address:       r.Address,
+			Deleted:       r.Deleted,
+			PlannedAction: r.PlannedAction,
+			Reverted:      r.Reverted,
+		}
+		for _, ac := range r.ChangedAttributes {
+			res.Paths = append(res.Paths, ac.Path)
+		}
+		actual = append(actual, res)
+	}
+
+	expected := []result{
+		{"aws_instance.tags", false, planquery.ActionUpdate, true, []string{"tags.Owner"}},
+		{"aws_instance.unrelated", false, planquery.ActionUpdate, false, []string{"size"}},
+		{"aws_instance.deleted", true, planquery.ActionCreate, true, []string{"id"}},
+		{"aws_instance.accepted", false, planquery.ActionNoOp, false, []string{"size"}},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAttributePathContains(t *testing.T) {
+	for _, c := range []struct {
+		parent   string
+		path     string
+		expected bool
+	}{
+		{"tags", "tags", true},
+		{"tags", "tags.Owner", true},
+		{"ports", "ports[0]", true},
+		{"tags", "tags_all", false},
+		{"tags.Owner", "tags", false},
+	} {
+		if actual := attributePathContains(c.parent, c.path); actual != c.expected {
+			t.Errorf("attributePathContains(%q, %q): expected %t, got %t", c.parent, c.path, c.expected, actual)
+		}
+	}
+}
+
+func TestDetectDrift_unsupportedOptions(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opt := range []PlanOption{
+		Destroy(true),
+		Out("plan.tfplan"),
+		RefreshOnly(true),
+		GenerateConfigOut("generated.tf"),
+	} {
+		_, err := tf.DetectDrift(context.Background(), opt)
+		if err == nil {
+			t.Fatalf("expected error for %T, got none", opt)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EncryptionConfig describes OpenTofu's client-side state and plan
+// encryption, equivalent to the encryption block of the terraform block.
+//
+// See https://opentofu.org/docs/language/state/encryption/ for the available
+// key providers and methods and their configuration.
+type EncryptionConfig struct {
+	KeyProviders []EncryptionKeyProvider
+	Methods      []EncryptionMethod
+
+	// State and Plan configure the encryption of state and plan files.
+	// A nil value leaves the respective files unencrypted.
+	State *EncryptionTarget
+	Plan  *EncryptionTarget
+
+	// RemoteStateDataSources configures the decryption of the state read
+	// by terraform_remote_state data sources.
+	RemoteStateDataSources *EncryptionRemoteStateDataSources
+}
+
+// EncryptionRef is a reference to a key provider or method, such as
+// key_provider.pbkdf2.mykey or method.aes_gcm.mymethod. Use the Ref methods
+// of EncryptionKeyProvider and EncryptionMethod to build one.
+//
+// Values of this type in a Config map are rendered as references rather than
+// as strings.
+type EncryptionRef string
+
+// EncryptionKeyProvider represents a key_provider block.
+type EncryptionKeyProvider struct {
+	// Type is the key provider type, for example pbkdf2 or aws_kms.
+	Type string
+	Name string
+
+	// Config holds the key provider arguments. Values may be strings,
+	// booleans, numbers, EncryptionRefs, slices of those, or nested
+	// map[string]interface{} values which are rendered as blocks.
+	Config map[string]interface{}
+}
+
+// Ref returns a reference to the key provider, for use as the keys of an
+// EncryptionMethod.
+func (kp EncryptionKeyProvider) Ref() EncryptionRef {
+	return EncryptionRef("key_provider." + kp.Type + "." + kp.Name)
+}
+
+// EncryptionMethod represents a method block.
+type EncryptionMethod struct {
+	// Type is the method type, for example aes_gcm or unencrypted.
+	Type string
+	Name string
+
+	// Keys references the key provider used by the method. It must be
+	// empty for the unencrypted method.
+	Keys EncryptionRef
+
+	// Config holds any further method arguments, see
+	// EncryptionKeyProvider.Config for the supported values.
+	Config map[string]interface{}
+}
+
+// Ref returns a reference to the method, for use in an EncryptionTarget.
+func (m EncryptionMethod) Ref() EncryptionRef {
+	return EncryptionRef("method." + m.Type + "." + m.Name)
+}
+
+// EncryptionTarget represents a state, plan or remote state data source
+// block.
+type EncryptionTarget struct {
+	Method EncryptionRef
+
+	// Fallback is the method used to read files which cannot be read with
+	// Method, typically used when migrating between methods or keys.
+	Fallback EncryptionRef
+
+	// Enforced makes OpenTofu refuse to write unencrypted files.
+	Enforced bool
+}
+
+// EncryptionRemoteStateDataSources represents the remote_state_data_sources
+// block.
+type EncryptionRemoteStateDataSources struct {
+	Default *EncryptionTarget
+
+	// DataSources is keyed by the address of the data source, for example
+	// terraform_remote_state.foo.
+	DataSources map[string]EncryptionTarget
+}
+
+var (
+	encryptionIdentRegexp  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	encryptionStringRegexp = regexp.MustCompile(`[=:]\s*("(?:[^"\\]|\\.)*")`)
+)
+
+// SetEncryption configures client-side state and plan encryption for all
+// commands by setting the TF_ENCRYPTION environment variable. Pass nil to
+// stop managing TF_ENCRYPTION, in which case any value inherited from
+// os.Environ is used.
+func (tf *Tofu) SetEncryption(config *EncryptionConfig) error {
+	rendered := ""
+	if config != nil {
+		var err error
+		rendered, err = config.render()
+		if err != nil {
+			return err
+		}
+	}
+
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.encryption = rendered
+	return nil
+}
+
+// render returns the configuration in the HCL syntax expected in the
+// TF_ENCRYPTION environment variable.
+func (c *EncryptionConfig) render() (string, error) {
+	var b strings.Builder
+
+	for _, kp := range c.KeyProviders {
+		if err := validateEncryptionLabels("key_provider", kp.Type, kp.Name); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "key_provider %q %q {\n", kp.Type, kp.Name)
+		if err := renderEncryptionBody(&b, 1, kp.Config); err != nil {
+			return "", fmt.Errorf("invalid key_provider %q %q: %w", kp.Type, kp.Name, err)
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, m := range c.Methods {
+		if err := validateEncryptionLabels("method", m.Type, m.Name); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "method %q %q {\n", m.Type, m.Name)
+		if m.Keys != "" {
+			fmt.Fprintf(&b, "  keys = %s\n", m.Keys)
+		}
+		if err := renderEncryptionBody(&b, 1, m.Config); err != nil {
+			return "", fmt.Errorf("invalid method %q %q: %w", m.Type, m.Name, err)
+		}
+		b.WriteString("}\n")
+	}
+
+	if c.State != nil {
+		renderEncryptionTarget(&b, 0, "state", c.State)
+	}
+	if c.Plan != nil {
+		renderEncryptionTarget(&b, 0, "plan", c.Plan)
+	}
+
+	if ds := c.RemoteStateDataSources; ds != nil {
+		b.WriteString("remote_state_data_sources {\n")
+		if ds.Default != nil {
+			renderEncryptionTarget(&b, 1, "default", ds.Default)
+		}
+		addrs := make([]string, 0, len(ds.DataSources))
+		for addr := range ds.DataSources {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			target := ds.DataSources[addr]
+			renderEncryptionTarget(&b, 1, fmt.Sprintf("remote_state_data_source %q", addr), &target)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+func validateEncryptionLabels(block string, typ string, name string) error {
+	if !encryptionIdentRegexp.MatchString(typ) {
+		return fmt.Errorf("invalid %s type %q", block, typ)
+	}
+	if !encryptionIdentRegexp.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q", block, name)
+	}
+	return nil
+}
+
+func renderEncryptionTarget(b *strings.Builder, indent int, header string, t *EncryptionTarget) {
+	pad := strings.Repeat("  ", indent)
+
+	fmt.Fprintf(b, "%s%s {\n", pad, header)
+	if t.Method != "" {
+		fmt.Fprintf(b, "%s  method = %s\n", pad, t.Method)
+	}
+	if t.Enforced {
+		fmt.Fprintf(b, "%s  enforced = true\n", pad)
+	}
+	if t.Fallback != "" {
+		fmt.Fprintf(b, "%s  fallback {\n", pad)
+		fmt.Fprintf(b, "%s    method = %s\n", pad, t.Fallback)
+		fmt.Fprintf(b, "%s  }\n", pad)
+	}
+	fmt.Fprintf(b, "%s}\n", pad)
+}
+
+func renderEncryptionBody(b *strings.Builder, indent int, config map[string]interface{}) error {
+	pad := strings.Repeat("  ", indent)
+
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !encryptionIdentRegexp.MatchString(k) {
+			return fmt.Errorf("invalid argument name %q", k)
+		}
+
+		if block, ok := config[k].(map[string]interface{}); ok {
+			fmt.Fprintf(b, "%s%s {\n", pad, k)
+			if err := renderEncryptionBody(b, indent+1, block); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "%s}\n", pad)
+			continue
+		}
+
+		v, err := renderEncryptionValue(config[k])
+		if err != nil {
+			return fmt.Errorf("invalid value for argument %q: %w", k, err)
+		}
+		fmt.Fprintf(b, "%s%s = %s\n", pad, k, v)
+	}
+
+	return nil
+}
+
+func renderEncryptionValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case EncryptionRef:
+		return string(v), nil
+	case string:
+		return quoteHCLString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []string:
+		elems := make([]string, len(v))
+		for i, s := range v {
+			elems[i] = quoteHCLString(s)
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, e := range v {
+			s, err := renderEncryptionValue(e)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = s
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	}
+	return "", fmt.Errorf("unsupported type %T", v)
+}
+
+// encryptionSecrets returns the string arguments of an encryption
+// configuration, such as passphrases, which are redacted on their own as
+// OpenTofu may print them outside of the configuration.
+func encryptionSecrets(config string) []string {
+	var secrets []string
+	for _, m := range encryptionStringRegexp.FindAllStringSubmatch(config, -1) {
+		s, err := strconv.Unquote(m[1])
+		if err != nil {
+			continue
+		}
+		secrets = append(secrets, templateUnescaper.Replace(s))
+	}
+	return secrets
+}
+
+// quoteHCLString returns s as a quoted HCL string literal, escaping template
+// sequences so that the value is taken literally.
+func quoteHCLString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteRune(r)
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteRune(r)
+			}
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
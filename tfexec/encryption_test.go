@@ -0,0 +1,194 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+)
+
+func TestEncryptionConfigRender(t *testing.T) {
+	passphrase := EncryptionKeyProvider{
+		Type: "pbkdf2",
+		Name: "mykey",
+		Config: map[string]interface{}{
+			"passphrase":    `correct "horse" ${battery}`,
+			"key_length":    32,
+			"hash_function": "sha512",
+		},
+	}
+	kms := EncryptionKeyProvider{
+		Type: "aws_kms",
+		Name: "kms",
+		Config: map[string]interface{}{
+			"kms_key_id": "alias/tofu",
+			"key_spec":   "AES_256",
+			"assume_role": map[string]interface{}{
+				"role_arn": "arn:aws:iam::123456789012:role/tofu",
+			},
+		},
+	}
+	aesGCM := EncryptionMethod{
+		Type: "aes_gcm",
+		Name: "new",
+		Keys: passphrase.Ref(),
+	}
+	unencrypted := EncryptionMethod{
+		Type: "unencrypted",
+		Name: "migrate",
+	}
+
+	config := &EncryptionConfig{
+		KeyProviders: []EncryptionKeyProvider{passphrase, kms},
+		Methods:      []EncryptionMethod{aesGCM, unencrypted},
+		State: &EncryptionTarget{
+			Method:   aesGCM.Ref(),
+			Fallback: unencrypted.Ref(),
+		},
+		Plan: &EncryptionTarget{
+			Method:   aesGCM.Ref(),
+			Enforced: true,
+		},
+		RemoteStateDataSources: &EncryptionRemoteStateDataSources{
+			Default: &EncryptionTarget{
+				Method: aesGCM.Ref(),
+			},
+			DataSources: map[string]EncryptionTarget{
+				"terraform_remote_state.legacy": {
+					Method: unencrypted.Ref(),
+				},
+			},
+		},
+	}
+
+	actual, err := config.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `key_provider "pbkdf2" "mykey" {
+  hash_function = "sha512"
+  key_length = 32
+  passphrase = "correct \"horse\" $${battery}"
+}
+key_provider "aws_kms" "kms" {
+  assume_role {
+    role_arn = "arn:aws:iam::123456789012:role/tofu"
+  }
+  key_spec = "AES_256"
+  kms_key_id = "alias/tofu"
+}
+method "aes_gcm" "new" {
+  keys = key_provider.pbkdf2.mykey
+}
+method "unencrypted" "migrate" {
+}
+state {
+  method = method.aes_gcm.new
+  fallback {
+    method = method.unencrypted.migrate
+  }
+}
+plan {
+  method = method.aes_gcm.new
+  enforced = true
+}
+remote_state_data_sources {
+  default {
+    method = method.aes_gcm.new
+  }
+  remote_state_data_source "terraform_remote_state.legacy" {
+    method = method.unencrypted.migrate
+  }
+}
+`
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestEncryptionConfigRender_invalid(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		config *EncryptionConfig
+	}{
+		{
+			"invalid key provider type",
+			&EncryptionConfig{
+				KeyProviders: []EncryptionKeyProvider{{Type: "pbkdf2\" \"x", Name: "key"}},
+			},
+		},
+		{
+			"invalid method name",
+			&EncryptionConfig{
+				Methods: []EncryptionMethod{{Type: "aes_gcm", Name: ""}},
+			},
+		},
+		{
+			"invalid argument name",
+			&EncryptionConfig{
+				KeyProviders: []EncryptionKeyProvider{{Type: "pbkdf2", Name: "key", Config: map[string]interface{}{"pass phrase": "x"}}},
+			},
+		},
+		{
+			"unsupported value",
+			&EncryptionConfig{
+				KeyProviders: []EncryptionKeyProvider{{Type: "pbkdf2", Name: "key", Config: map[string]interface{}{"passphrase": struct{}{}}}},
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.config.render()
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+		})
+	}
+}
+
+func TestSetEncryption(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// empty env, to avoid environ mismatch in testing
+	tf.SetEnv(map[string]string{})
+
+	err = tf.SetEncryption(&EncryptionConfig{
+		KeyProviders: []EncryptionKeyProvider{{
+			Type:   "pbkdf2",
+			Name:   "mykey",
+			Config: map[string]interface{}{"passphrase": "correct-horse-battery-staple"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statePullCmd := tf.statePullCmd(context.Background(), nil)
+	assertCmd(t, []string{
+		"state",
+		"pull",
+	}, map[string]string{
+		"TF_ENCRYPTION": "key_provider \"pbkdf2\" \"mykey\" {\n  passphrase = \"correct-horse-battery-staple\"\n}\n",
+	}, statePullCmd)
+
+	err = tf.SetEncryption(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statePullCmd = tf.statePullCmd(context.Background(), nil)
+	assertCmd(t, []string{
+		"state",
+		"pull",
+	}, nil, statePullCmd)
+}
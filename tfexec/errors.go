@@ -7,10 +7,16 @@ package tfexec
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
 )
 
-// this file contains non-parsed exported errors
+// this file contains exported errors, including errors classified from the
+// diagnostics or stderr output of a failed command
 
 type ErrNoSuitableBinary struct {
 	err error
@@ -46,6 +52,41 @@ func (err *ErrManualEnvVar) Error() string {
 	return fmt.Sprintf("manual setting of env var %q detected", err.Name)
 }
 
+// DiagnosticsError is returned when a command run with the -json flag exits
+// with an error after emitting diagnostics on its machine-readable UI.
+//
+// The underlying error, typically an *exec.ExitError, is available through
+// errors.As.
+type DiagnosticsError struct {
+	// Diagnostics holds every diagnostic emitted by the command, including
+	// warnings.
+	Diagnostics []tfjson.Diagnostic
+
+	err error
+}
+
+func (e *DiagnosticsError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.err.Error())
+	for _, diag := range e.Diagnostics {
+		if diag.Severity != tfjson.DiagnosticSeverityError {
+			continue
+		}
+		b.WriteString("\nError: ")
+		b.WriteString(diag.Summary)
+		if diag.Detail != "" {
+			b.WriteString("\n\n")
+			b.WriteString(diag.Detail)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (e *DiagnosticsError) Unwrap() error {
+	return e.err
+}
+
 // cmdErr is a custom error type to be returned when a cmd exits with a context
 // error such as context.Canceled or context.DeadlineExceeded.
 // The type is specifically designed to respond true to errors.Is for these two
@@ -54,6 +95,10 @@ func (err *ErrManualEnvVar) Error() string {
 type cmdErr struct {
 	err    error
 	ctxErr error
+
+	// stage is the stage of the cancellation which ended the process, see
+	// CancelStageOf.
+	stage CancelStage
 }
 
 func (e cmdErr) Is(target error) bool {
@@ -67,3 +112,385 @@ func (e cmdErr) Is(target error) bool {
 func (e cmdErr) Error() string {
 	return e.err.Error()
 }
+
+func (e cmdErr) Unwrap() error {
+	return e.err
+}
+
+var (
+	// ErrStateLocked is matched by errors.Is when a command failed because
+	// the state is locked. Use errors.As with *StateLockedError to access
+	// the lock information, for example to pass its ID to ForceUnlock.
+	ErrStateLocked = errors.New("state locked")
+
+	// ErrNoInit is matched by errors.Is when a command failed because the
+	// working directory has not been initialized with Init.
+	ErrNoInit = errors.New("working directory not initialized")
+
+	// ErrMissingVar is matched by errors.Is when a command failed because a
+	// required root module variable was not set. Use errors.As with
+	// *MissingVarError to access the variable name.
+	ErrMissingVar = errors.New("missing required variable")
+
+	// ErrWorkspaceExists is matched by errors.Is when WorkspaceNew failed
+	// because the workspace already exists. Use errors.As with
+	// *WorkspaceExistsError to access the workspace name.
+	ErrWorkspaceExists = errors.New("workspace already exists")
+
+	// ErrStalePlan is matched by errors.Is when Apply refused to apply a
+	// saved plan because its provenance no longer matches. Use errors.As
+	// with *StalePlanError to access the mismatches.
+	ErrStalePlan = errors.New("saved plan is stale")
+
+	// ErrPlanRejected is matched by errors.Is when PlanAndApply did not apply
+	// the plan because the Approver rejected it. Use errors.As with
+	// *PlanRejectedError to access the reason.
+	ErrPlanRejected = errors.New("plan rejected")
+
+	// ErrPolicyViolation is matched by errors.Is when Apply refused to apply
+	// a saved plan because it violates a policy. Use errors.As with
+	// *PolicyViolationError to access the violations.
+	ErrPolicyViolation = errors.New("plan violates policy")
+
+	// ErrReadOnly is matched by errors.Is when a command was refused because
+	// it can change infrastructure or state and the Tofu instance is in
+	// read-only mode, see SetReadOnly.
+	ErrReadOnly = errors.New("tofu is read-only")
+)
+
+// LockInfo describes the holder of a state lock, as reported by OpenTofu.
+type LockInfo struct {
+	ID        string
+	Path      string
+	Operation string
+	Who       string
+	Version   string
+	Created   string
+	Info      string
+}
+
+// StateLockedError is returned when a command failed to acquire the state
+// lock. It matches ErrStateLocked.
+type StateLockedError struct {
+	LockInfo LockInfo
+
+	err error
+}
+
+func (e *StateLockedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *StateLockedError) Unwrap() error {
+	return e.err
+}
+
+func (e *StateLockedError) Is(target error) bool {
+	return target == ErrStateLocked
+}
+
+// NoInitError is returned when a command requires Init to be run first. It
+// matches ErrNoInit.
+type NoInitError struct {
+	err error
+}
+
+func (e *NoInitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *NoInitError) Unwrap() error {
+	return e.err
+}
+
+func (e *NoInitError) Is(target error) bool {
+	return target == ErrNoInit
+}
+
+// MissingVarError is returned when a required root module variable was not
+// set. It matches ErrMissingVar.
+type MissingVarError struct {
+	VariableName string
+
+	err error
+}
+
+func (e *MissingVarError) Error() string {
+	return e.err.Error()
+}
+
+func (e *MissingVarError) Unwrap() error {
+	return e.err
+}
+
+func (e *MissingVarError) Is(target error) bool {
+	return target == ErrMissingVar
+}
+
+// WorkspaceExistsError is returned when creating a workspace that already
+// exists. It matches ErrWorkspaceExists.
+type WorkspaceExistsError struct {
+	Name string
+
+	err error
+}
+
+func (e *WorkspaceExistsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *WorkspaceExistsError) Unwrap() error {
+	return e.err
+}
+
+func (e *WorkspaceExistsError) Is(target error) bool {
+	return target == ErrWorkspaceExists
+}
+
+// noInitSummaries are the summaries of diagnostics which ask the user to run
+// tofu init.
+var noInitSummaries = []string{
+	"Backend initialization required",
+	"Required plugins are not installed",
+	"Module not installed",
+	"Inconsistent dependency lock file",
+	"Missing required provider",
+}
+
+var (
+	workspaceExistsRegexp = regexp.MustCompile(`Workspace "([^"]+)" already exists`)
+	quotedNameRegexp      = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// jsonDiagnosticsCommands are the subcommands that report their diagnostics
+// in machine-readable form when run with the -json flag.
+var jsonDiagnosticsCommands = map[string]bool{
+	"apply":    true,
+	"destroy":  true,
+	"init":     true,
+	"plan":     true,
+	"refresh":  true,
+	"test":     true,
+	"validate": true,
+}
+
+// classifyExitError wraps err with one of the classified error types if the
+// error diagnostics match a well known failure. Otherwise err is returned as
+// is.
+//
+// The human-readable diagnostics printed on stderr are parsed as a fallback
+// only for commands that cannot emit JSON diagnostics at all, such as
+// workspace new or state rm. This text is not a stable interface, so commands
+// with a -json variant are only classified from their JSON diagnostics.
+func classifyExitError(err error, args []string, stderr string, diags []tfjson.Diagnostic) error {
+	if len(diags) > 0 {
+		return classifyDiagnostics(err, diags)
+	}
+	if jsonDiagnosticsCommands[subcommand(args)] {
+		return err
+	}
+
+	if m := workspaceExistsRegexp.FindStringSubmatch(stderr); m != nil {
+		return &WorkspaceExistsError{Name: m[1], err: err}
+	}
+
+	return classifyDiagnostics(err, parseStderrDiagnostics(stderr))
+}
+
+// classifyDiagnostics wraps err with the classified error type of the first
+// error diagnostic matching a well known failure.
+func classifyDiagnostics(err error, diags []tfjson.Diagnostic) error {
+	for _, diag := range diags {
+		if diag.Severity != tfjson.DiagnosticSeverityError {
+			continue
+		}
+
+		switch {
+		case diag.Summary == "Error acquiring the state lock":
+			return &StateLockedError{LockInfo: parseLockInfo(diag.Detail), err: err}
+		case diag.Summary == "No value for required variable":
+			var name string
+			if m := quotedNameRegexp.FindStringSubmatch(diag.Detail); m != nil {
+				name = m[1]
+			}
+			return &MissingVarError{VariableName: name, err: err}
+		}
+
+		for _, summary := range noInitSummaries {
+			if strings.HasPrefix(diag.Summary, summary) {
+				return &NoInitError{err: err}
+			}
+		}
+	}
+
+	return err
+}
+
+// parseStderrDiagnostics extracts error diagnostics from the human-readable
+// output of a command, such as:
+//
+//	╷
+//	│ Error: Error acquiring the state lock
+//	│
+//	│ Error message: ...
+//	╵
+func parseStderrDiagnostics(stderr string) []tfjson.Diagnostic {
+	var diags []tfjson.Diagnostic
+	var detail []string
+
+	flush := func() {
+		if len(diags) == 0 {
+			return
+		}
+		diags[len(diags)-1].Detail = strings.TrimSpace(strings.Join(detail, "\n"))
+		detail = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(stderr, "\r\n", "\n"), "\n") {
+		line = strings.TrimLeft(line, "╷│╵")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "Error: "):
+			flush()
+			diags = append(diags, tfjson.Diagnostic{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  strings.TrimPrefix(trimmed, "Error: "),
+			})
+		case strings.HasPrefix(trimmed, "Warning: "):
+			flush()
+			diags = append(diags, tfjson.Diagnostic{
+				Severity: tfjson.DiagnosticSeverityWarning,
+				Summary:  strings.TrimPrefix(trimmed, "Warning: "),
+			})
+		default:
+			detail = append(detail, line)
+		}
+	}
+	flush()
+
+	return diags
+}
+
+// parseLockInfo extracts the lock information from the detail of an
+// "Error acquiring the state lock" diagnostic.
+func parseLockInfo(detail string) LockInfo {
+	var info LockInfo
+	fields := map[string]*string{
+		"ID":        &info.ID,
+		"Path":      &info.Path,
+		"Operation": &info.Operation,
+		"Who":       &info.Who,
+		"Version":   &info.Version,
+		"Created":   &info.Created,
+		"Info":      &info.Info,
+	}
+
+	inLockInfo := false
+	for _, line := range strings.Split(detail, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "Lock Info:" {
+			inLockInfo = true
+			continue
+		}
+		if !inLockInfo {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if field, ok := fields[key]; ok {
+			*field = strings.TrimSpace(value)
+		}
+	}
+
+	return info
+}
+
+// ProvenanceMismatch describes a field of a PlanProvenance which differs
+// between the recorded provenance and the current one.
+type ProvenanceMismatch struct {
+	// Field is the JSON name of the field, for example state_serial.
+	Field    string
+	Recorded string
+	Current  string
+}
+
+// StalePlanError is returned by Apply when verifying the provenance of a
+// saved plan failed because the plan file, the state, the workspace or the
+// OpenTofu version changed since the plan was created. It matches
+// ErrStalePlan.
+type StalePlanError struct {
+	PlanFile   string
+	Mismatches []ProvenanceMismatch
+}
+
+func (e *StalePlanError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "saved plan %s is stale:", e.PlanFile)
+	for i, m := range e.Mismatches {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, " %s changed from %q to %q", m.Field, m.Recorded, m.Current)
+	}
+	return b.String()
+}
+
+func (e *StalePlanError) Is(target error) bool {
+	return target == ErrStalePlan
+}
+
+// PlanRejectedError is returned by PlanAndApply when the Approver rejected
+// the plan. It matches ErrPlanRejected.
+type PlanRejectedError struct {
+	Reason string
+}
+
+func (e *PlanRejectedError) Error() string {
+	if e.Reason == "" {
+		return "plan rejected"
+	}
+	return "plan rejected: " + e.Reason
+}
+
+func (e *PlanRejectedError) Is(target error) bool {
+	return target == ErrPlanRejected
+}
+
+// PolicyViolationError is returned by Apply when the saved plan violates any
+// of the policies passed with the Policies option. It lists every violation
+// and matches ErrPolicyViolation.
+type PolicyViolationError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyViolationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "plan violates %d policy rule(s):", len(e.Violations))
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n  - %s: %s", v.Policy, v.Message)
+	}
+	return b.String()
+}
+
+func (e *PolicyViolationError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// ReadOnlyError is returned when a command was refused because the Tofu
+// instance is in read-only mode. It matches ErrReadOnly.
+type ReadOnlyError struct {
+	// Command is the refused command, for example "state rm".
+	Command string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("refusing to run %q in read-only mode", e.Command)
+}
+
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
@@ -0,0 +1,182 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const lockedStderr = `
+╷
+│ Error: Error acquiring the state lock
+│ 
+│ Error message: state locked
+│ Lock Info:
+│   ID:        2b6a6738-5dd5-50d6-c0ae-f6352977666b
+│   Path:      
+│   Operation: OperationTypeApply
+│   Who:       tofu@example
+│   Version:   1.10.5
+│   Created:   2024-01-02 03:04:05.000000000 +0000 UTC
+│   Info:      
+│ 
+│ 
+│ OpenTofu acquires a state lock to protect the state from being written
+│ by multiple users at the same time.
+╵
+`
+
+func TestClassifyExitError(t *testing.T) {
+	baseErr := errors.New("exit status 1")
+	planArgs := []string{"plan", "-no-color", "-json"}
+	stateRmArgs := []string{"state", "rm", "-no-color", "null_resource.foo"}
+
+	t.Run("state locked from stderr of command without JSON diagnostics", func(t *testing.T) {
+		err := classifyExitError(baseErr, stateRmArgs, lockedStderr, nil)
+
+		if !errors.Is(err, ErrStateLocked) {
+			t.Fatalf("expected ErrStateLocked, got %T %s", err, err)
+		}
+		if !errors.Is(err, baseErr) {
+			t.Fatal("expected classified error to wrap original error")
+		}
+
+		var lockErr *StateLockedError
+		if !errors.As(err, &lockErr) {
+			t.Fatalf("expected StateLockedError, got %T", err)
+		}
+
+		expected := LockInfo{
+			ID:        "2b6a6738-5dd5-50d6-c0ae-f6352977666b",
+			Operation: "OperationTypeApply",
+			Who:       "tofu@example",
+			Version:   "1.10.5",
+			Created:   "2024-01-02 03:04:05.000000000 +0000 UTC",
+		}
+		if diff := cmp.Diff(expected, lockErr.LockInfo); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing var from diagnostics", func(t *testing.T) {
+		err := classifyExitError(baseErr, planArgs, "", []tfjson.Diagnostic{
+			{
+				Severity: tfjson.DiagnosticSeverityWarning,
+				Summary:  "Deprecated",
+			},
+			{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "No value for required variable",
+				Detail:   `The root module input variable "no_default" is not set, and has no default value.`,
+			},
+		})
+
+		var varErr *MissingVarError
+		if !errors.As(err, &varErr) {
+			t.Fatalf("expected MissingVarError, got %T %s", err, err)
+		}
+		if !errors.Is(err, ErrMissingVar) {
+			t.Fatal("expected error to match ErrMissingVar")
+		}
+		if varErr.VariableName != "no_default" {
+			t.Fatalf("expected variable name %q, got %q", "no_default", varErr.VariableName)
+		}
+	})
+
+	t.Run("no init from diagnostics", func(t *testing.T) {
+		err := classifyExitError(baseErr, planArgs, "", []tfjson.Diagnostic{
+			{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  `Backend initialization required, please run "tofu init"`,
+			},
+		})
+
+		if !errors.Is(err, ErrNoInit) {
+			t.Fatalf("expected ErrNoInit, got %T %s", err, err)
+		}
+	})
+
+	t.Run("workspace exists from stderr without diagnostics", func(t *testing.T) {
+		err := classifyExitError(baseErr, []string{"workspace", "new", "foo"}, "Workspace \"foo\" already exists\n", nil)
+
+		var wsErr *WorkspaceExistsError
+		if !errors.As(err, &wsErr) {
+			t.Fatalf("expected WorkspaceExistsError, got %T %s", err, err)
+		}
+		if wsErr.Name != "foo" {
+			t.Fatalf("expected workspace name %q, got %q", "foo", wsErr.Name)
+		}
+	})
+
+	t.Run("diagnostics before stderr", func(t *testing.T) {
+		err := classifyExitError(baseErr, planArgs, "Workspace \"foo\" already exists\n"+lockedStderr, []tfjson.Diagnostic{
+			{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "No value for required variable",
+				Detail:   `The root module input variable "no_default" is not set, and has no default value.`,
+			},
+		})
+
+		if !errors.Is(err, ErrMissingVar) {
+			t.Fatalf("expected ErrMissingVar, got %T %s", err, err)
+		}
+		if errors.Is(err, ErrWorkspaceExists) || errors.Is(err, ErrStateLocked) {
+			t.Fatal("expected stderr to be ignored when diagnostics were emitted")
+		}
+	})
+
+	t.Run("unclassified diagnostics", func(t *testing.T) {
+		err := classifyExitError(baseErr, planArgs, lockedStderr, []tfjson.Diagnostic{
+			{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "Unsupported argument",
+			},
+		})
+		if err != baseErr {
+			t.Fatalf("expected original error, got %T %s", err, err)
+		}
+	})
+
+	t.Run("stderr ignored for commands with JSON diagnostics", func(t *testing.T) {
+		for _, args := range [][]string{
+			planArgs,
+			{"plan", "-no-color"},
+			{"apply", "-no-color", "-auto-approve"},
+			{"init", "-no-color"},
+		} {
+			err := classifyExitError(baseErr, args, lockedStderr, nil)
+			if err != baseErr {
+				t.Fatalf("%v: expected original error, got %T %s", args, err, err)
+			}
+		}
+	})
+
+	t.Run("unclassified", func(t *testing.T) {
+		err := classifyExitError(baseErr, stateRmArgs, "Error: Unsupported argument\n", nil)
+		if err != baseErr {
+			t.Fatalf("expected original error, got %T %s", err, err)
+		}
+	})
+
+	t.Run("context semantics", func(t *testing.T) {
+		err := error(cmdErr{
+			err:    classifyExitError(baseErr, stateRmArgs, lockedStderr, nil),
+			ctxErr: context.Canceled,
+		})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal("expected error to match context.Canceled")
+		}
+		if !errors.Is(err, ErrStateLocked) {
+			t.Fatal("expected error to match ErrStateLocked")
+		}
+	})
+}
@@ -0,0 +1,466 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// EventType is the value of the "type" field of a message in OpenTofu's
+// machine-readable UI (the output of commands run with the -json flag).
+type EventType string
+
+const (
+	EventTypeVersion           EventType = "version"
+	EventTypeLog               EventType = "log"
+	EventTypeDiagnostic        EventType = "diagnostic"
+	EventTypeResourceDrift     EventType = "resource_drift"
+	EventTypePlannedChange     EventType = "planned_change"
+	EventTypeChangeSummary     EventType = "change_summary"
+	EventTypeOutputs           EventType = "outputs"
+	EventTypeApplyStart        EventType = "apply_start"
+	EventTypeApplyProgress     EventType = "apply_progress"
+	EventTypeApplyComplete     EventType = "apply_complete"
+	EventTypeApplyErrored      EventType = "apply_errored"
+	EventTypeProvisionStart    EventType = "provision_start"
+	EventTypeProvisionProgress EventType = "provision_progress"
+	EventTypeProvisionComplete EventType = "provision_complete"
+	EventTypeProvisionErrored  EventType = "provision_errored"
+	EventTypeRefreshStart      EventType = "refresh_start"
+	EventTypeRefreshComplete   EventType = "refresh_complete"
+	EventTypeTestAbstract      EventType = "test_abstract"
+	EventTypeTestFile          EventType = "test_file"
+	EventTypeTestRun           EventType = "test_run"
+	EventTypeTestPlan          EventType = "test_plan"
+	EventTypeTestState         EventType = "test_state"
+	EventTypeTestCleanup       EventType = "test_cleanup"
+	EventTypeTestInterrupt     EventType = "test_interrupt"
+	EventTypeTestSummary       EventType = "test_summary"
+)
+
+// ChangeAction is the action reported for a resource instance or output in
+// machine-readable UI messages.
+type ChangeAction string
+
+const (
+	ChangeActionNoOp    ChangeAction = "noop"
+	ChangeActionMove    ChangeAction = "move"
+	ChangeActionCreate  ChangeAction = "create"
+	ChangeActionRead    ChangeAction = "read"
+	ChangeActionUpdate  ChangeAction = "update"
+	ChangeActionReplace ChangeAction = "replace"
+	ChangeActionDelete  ChangeAction = "delete"
+	ChangeActionImport  ChangeAction = "import"
+)
+
+// Event is implemented by every message type of OpenTofu's machine-readable
+// UI. Use a type switch to access the message specific fields.
+type Event interface {
+	Header() EventHeader
+}
+
+// EventHandler is called once for every machine-readable UI message emitted
+// by a command, in the order they were emitted.
+type EventHandler func(Event)
+
+// EventHeader holds the fields common to every machine-readable UI message.
+type EventHeader struct {
+	Level     string    `json:"@level"`
+	Message   string    `json:"@message"`
+	Module    string    `json:"@module"`
+	Timestamp time.Time `json:"@timestamp"`
+	Type      EventType `json:"type"`
+
+	// TestFile and TestRun are only set on messages emitted by tofu test.
+	TestFile string `json:"@testfile,omitempty"`
+	TestRun  string `json:"@testrun,omitempty"`
+}
+
+// Header returns the common message fields.
+func (h EventHeader) Header() EventHeader {
+	return h
+}
+
+// UnknownEvent is returned for any message type not known to this version of
+// tfexec, for example messages introduced in newer versions of OpenTofu.
+type UnknownEvent struct {
+	EventHeader
+
+	// Raw is the full, undecoded message.
+	Raw json.RawMessage
+}
+
+// VersionEvent is the first message emitted by every command.
+type VersionEvent struct {
+	EventHeader
+	Tofu string `json:"tofu"`
+	UI   string `json:"ui"`
+}
+
+// LogEvent is a free-form informational message.
+type LogEvent struct {
+	EventHeader
+}
+
+// DiagnosticEvent carries a single warning or error.
+type DiagnosticEvent struct {
+	EventHeader
+	Diagnostic tfjson.Diagnostic `json:"diagnostic"`
+}
+
+// ResourceAddr identifies the resource instance a message refers to.
+type ResourceAddr struct {
+	Addr            string      `json:"addr"`
+	Module          string      `json:"module"`
+	Resource        string      `json:"resource"`
+	ImpliedProvider string      `json:"implied_provider"`
+	ResourceType    string      `json:"resource_type"`
+	ResourceName    string      `json:"resource_name"`
+	ResourceKey     interface{} `json:"resource_key"`
+}
+
+// ImportingChange describes an import that is part of a planned change.
+type ImportingChange struct {
+	ID string `json:"id"`
+}
+
+// ResourceInstanceChange describes a single planned or drifted change.
+type ResourceInstanceChange struct {
+	Resource         ResourceAddr     `json:"resource"`
+	PreviousResource *ResourceAddr    `json:"previous_resource,omitempty"`
+	Action           ChangeAction     `json:"action"`
+	Reason           string           `json:"reason,omitempty"`
+	Importing        *ImportingChange `json:"importing,omitempty"`
+	GeneratedConfig  string           `json:"generated_config,omitempty"`
+}
+
+// PlannedChangeEvent is emitted for every resource instance change in a plan.
+type PlannedChangeEvent struct {
+	EventHeader
+	Change ResourceInstanceChange `json:"change"`
+}
+
+// ResourceDriftEvent is emitted for every resource instance that changed
+// outside of OpenTofu.
+type ResourceDriftEvent struct {
+	EventHeader
+	Change ResourceInstanceChange `json:"change"`
+}
+
+// ChangeSummary holds the change counts of a plan, apply or destroy.
+type ChangeSummary struct {
+	Add       int    `json:"add"`
+	Change    int    `json:"change"`
+	Import    int    `json:"import"`
+	Remove    int    `json:"remove"`
+	Forget    int    `json:"forget"`
+	Operation string `json:"operation"`
+}
+
+// ChangeSummaryEvent is emitted once at the end of a plan, apply or destroy.
+type ChangeSummaryEvent struct {
+	EventHeader
+	Changes ChangeSummary `json:"changes"`
+}
+
+// OutputChange describes a root module output value. Value is omitted by
+// OpenTofu for outputs which are sensitive or not yet known.
+type OutputChange struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Action    ChangeAction    `json:"action,omitempty"`
+}
+
+// OutputsEvent lists the root module outputs after a plan or apply.
+type OutputsEvent struct {
+	EventHeader
+	Outputs map[string]OutputChange `json:"outputs"`
+}
+
+// OperationHook describes the progress of an apply or refresh of a single
+// resource instance.
+type OperationHook struct {
+	Resource       ResourceAddr `json:"resource"`
+	Action         ChangeAction `json:"action,omitempty"`
+	IDKey          string       `json:"id_key,omitempty"`
+	IDValue        string       `json:"id_value,omitempty"`
+	ElapsedSeconds float64      `json:"elapsed_seconds,omitempty"`
+}
+
+// ApplyStartEvent is emitted when OpenTofu starts applying a resource instance.
+type ApplyStartEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// ApplyProgressEvent is emitted periodically while a resource instance is
+// being applied.
+type ApplyProgressEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// ApplyCompleteEvent is emitted when a resource instance was applied.
+type ApplyCompleteEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// ApplyErroredEvent is emitted when applying a resource instance failed.
+type ApplyErroredEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// RefreshStartEvent is emitted when OpenTofu starts refreshing a resource
+// instance.
+type RefreshStartEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// RefreshCompleteEvent is emitted when a resource instance was refreshed.
+type RefreshCompleteEvent struct {
+	EventHeader
+	Hook OperationHook `json:"hook"`
+}
+
+// ProvisionHook describes the progress of a provisioner.
+type ProvisionHook struct {
+	Resource    ResourceAddr `json:"resource"`
+	Provisioner string       `json:"provisioner"`
+	Output      string       `json:"output,omitempty"`
+}
+
+// ProvisionStartEvent is emitted when a provisioner starts.
+type ProvisionStartEvent struct {
+	EventHeader
+	Hook ProvisionHook `json:"hook"`
+}
+
+// ProvisionProgressEvent is emitted for every line of provisioner output.
+type ProvisionProgressEvent struct {
+	EventHeader
+	Hook ProvisionHook `json:"hook"`
+}
+
+// ProvisionCompleteEvent is emitted when a provisioner finished.
+type ProvisionCompleteEvent struct {
+	EventHeader
+	Hook ProvisionHook `json:"hook"`
+}
+
+// ProvisionErroredEvent is emitted when a provisioner failed.
+type ProvisionErroredEvent struct {
+	EventHeader
+	Hook ProvisionHook `json:"hook"`
+}
+
+// TestStatus is the status of a test file, run block or whole test suite.
+type TestStatus string
+
+const (
+	TestStatusPending TestStatus = "pending"
+	TestStatusSkip    TestStatus = "skip"
+	TestStatusPass    TestStatus = "pass"
+	TestStatusFail    TestStatus = "fail"
+	TestStatusError   TestStatus = "error"
+)
+
+// TestAbstractEvent lists the test files and run blocks that will be
+// executed, keyed by test file path.
+type TestAbstractEvent struct {
+	EventHeader
+	Abstract map[string][]string `json:"test_abstract"`
+}
+
+// TestFileStatus is the status of a single test file.
+type TestFileStatus struct {
+	Path   string     `json:"path"`
+	Status TestStatus `json:"status"`
+}
+
+// TestFileEvent is emitted when a test file finished.
+type TestFileEvent struct {
+	EventHeader
+	File TestFileStatus `json:"test_file"`
+}
+
+// TestRunStatus is the status of a single run block.
+type TestRunStatus struct {
+	Path   string     `json:"path"`
+	Run    string     `json:"run"`
+	Status TestStatus `json:"status"`
+}
+
+// TestRunEvent is emitted when a run block finished.
+type TestRunEvent struct {
+	EventHeader
+	Run TestRunStatus `json:"test_run"`
+}
+
+// TestPlanEvent carries the plan produced by a run block, in the format
+// returned by ShowPlanFile.
+type TestPlanEvent struct {
+	EventHeader
+	Plan json.RawMessage `json:"test_plan"`
+}
+
+// TestStateEvent carries the state produced by a run block, in the format
+// returned by Show.
+type TestStateEvent struct {
+	EventHeader
+	State json.RawMessage `json:"test_state"`
+}
+
+// TestFailedResource identifies a resource that tofu test could not destroy.
+type TestFailedResource struct {
+	Instance   string `json:"instance"`
+	DeposedKey string `json:"deposed_key,omitempty"`
+}
+
+// TestCleanup lists resources left behind after a test file.
+type TestCleanup struct {
+	FailedResources []TestFailedResource `json:"failed_resources"`
+}
+
+// TestCleanupEvent is emitted when tofu test failed to clean up resources.
+type TestCleanupEvent struct {
+	EventHeader
+	Cleanup TestCleanup `json:"test_cleanup"`
+}
+
+// TestInterruptEvent is emitted when tofu test was interrupted. The payload
+// is left undecoded as its structure differs between OpenTofu versions.
+type TestInterruptEvent struct {
+	EventHeader
+	Interrupt json.RawMessage `json:"test_interrupt"`
+}
+
+// TestSummaryCounts is the overall result of a tofu test invocation.
+type TestSummaryCounts struct {
+	Status  TestStatus `json:"status"`
+	Passed  int        `json:"passed"`
+	Failed  int        `json:"failed"`
+	Errored int        `json:"errored"`
+	Skipped int        `json:"skipped"`
+}
+
+// TestSummaryEvent is emitted once at the end of tofu test.
+type TestSummaryEvent struct {
+	EventHeader
+	Summary TestSummaryCounts `json:"test_summary"`
+}
+
+// ParseEvent decodes a single line of OpenTofu's machine-readable UI output.
+//
+// Messages with a type unknown to this version of tfexec are returned as
+// *UnknownEvent rather than an error.
+func ParseEvent(line []byte) (Event, error) {
+	var header EventHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, fmt.Errorf("unable to parse event: %w", err)
+	}
+
+	var ev Event
+	switch header.Type {
+	case EventTypeVersion:
+		ev = &VersionEvent{}
+	case EventTypeLog:
+		ev = &LogEvent{}
+	case EventTypeDiagnostic:
+		ev = &DiagnosticEvent{}
+	case EventTypeResourceDrift:
+		ev = &ResourceDriftEvent{}
+	case EventTypePlannedChange:
+		ev = &PlannedChangeEvent{}
+	case EventTypeChangeSummary:
+		ev = &ChangeSummaryEvent{}
+	case EventTypeOutputs:
+		ev = &OutputsEvent{}
+	case EventTypeApplyStart:
+		ev = &ApplyStartEvent{}
+	case EventTypeApplyProgress:
+		ev = &ApplyProgressEvent{}
+	case EventTypeApplyComplete:
+		ev = &ApplyCompleteEvent{}
+	case EventTypeApplyErrored:
+		ev = &ApplyErroredEvent{}
+	case EventTypeProvisionStart:
+		ev = &ProvisionStartEvent{}
+	case EventTypeProvisionProgress:
+		ev = &ProvisionProgressEvent{}
+	case EventTypeProvisionComplete:
+		ev = &ProvisionCompleteEvent{}
+	case EventTypeProvisionErrored:
+		ev = &ProvisionErroredEvent{}
+	case EventTypeRefreshStart:
+		ev = &RefreshStartEvent{}
+	case EventTypeRefreshComplete:
+		ev = &RefreshCompleteEvent{}
+	case EventTypeTestAbstract:
+		ev = &TestAbstractEvent{}
+	case EventTypeTestFile:
+		ev = &TestFileEvent{}
+	case EventTypeTestRun:
+		ev = &TestRunEvent{}
+	case EventTypeTestPlan:
+		ev = &TestPlanEvent{}
+	case EventTypeTestState:
+		ev = &TestStateEvent{}
+	case EventTypeTestCleanup:
+		ev = &TestCleanupEvent{}
+	case EventTypeTestInterrupt:
+		ev = &TestInterruptEvent{}
+	case EventTypeTestSummary:
+		ev = &TestSummaryEvent{}
+	default:
+		raw := make(json.RawMessage, len(line))
+		copy(raw, line)
+		return &UnknownEvent{EventHeader: header, Raw: raw}, nil
+	}
+
+	if err := json.Unmarshal(line, ev); err != nil {
+		return nil, fmt.Errorf("unable to parse %q event: %w", header.Type, err)
+	}
+
+	return ev, nil
+}
+
+// eventWriter decodes machine-readable UI messages and passes them to an
+// EventHandler. It relies on writeOutput calling Write once per line.
+//
+// Lines that cannot be decoded are dropped rather than returned as an error,
+// as failing the Write would stop draining the command's stdout pipe.
+type eventWriter struct {
+	handler EventHandler
+}
+
+func newEventWriter(handler EventHandler) *eventWriter {
+	return &eventWriter{handler: handler}
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimSpace(p)
+	if len(line) == 0 || w.handler == nil {
+		return len(p), nil
+	}
+
+	ev, err := ParseEvent(line)
+	if err != nil {
+		return len(p), nil
+	}
+
+	w.handler(ev)
+
+	return len(p), nil
+}
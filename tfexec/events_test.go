@@ -0,0 +1,198 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestParseEvent(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	header := func(typ EventType, level, msg string) EventHeader {
+		return EventHeader{
+			Level:     level,
+			Message:   msg,
+			Module:    "tofu.ui",
+			Timestamp: ts,
+			Type:      typ,
+		}
+	}
+	nullFoo := ResourceAddr{
+		Addr:            "null_resource.foo",
+		Module:          "",
+		Resource:        "null_resource.foo",
+		ImpliedProvider: "null",
+		ResourceType:    "null_resource",
+		ResourceName:    "foo",
+		ResourceKey:     nil,
+	}
+
+	for _, c := range []struct {
+		name     string
+		line     string
+		expected Event
+	}{
+		{
+			"version",
+			`{"@level":"info","@message":"OpenTofu 1.10.5","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","tofu":"1.10.5","type":"version","ui":"1.2"}`,
+			&VersionEvent{
+				EventHeader: header(EventTypeVersion, "info", "OpenTofu 1.10.5"),
+				Tofu:        "1.10.5",
+				UI:          "1.2",
+			},
+		},
+		{
+			"diagnostic",
+			`{"@level":"error","@message":"Error: Missing required argument","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","diagnostic":{"severity":"error","summary":"Missing required argument","detail":"The argument \"foo\" is required.","range":{"filename":"main.tf","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":10,"byte":9}}},"type":"diagnostic"}`,
+			&DiagnosticEvent{
+				EventHeader: header(EventTypeDiagnostic, "error", "Error: Missing required argument"),
+				Diagnostic: tfjson.Diagnostic{
+					Severity: tfjson.DiagnosticSeverityError,
+					Summary:  "Missing required argument",
+					Detail:   `The argument "foo" is required.`,
+					Range: &tfjson.Range{
+						Filename: "main.tf",
+						Start:    tfjson.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      tfjson.Pos{Line: 1, Column: 10, Byte: 9},
+					},
+				},
+			},
+		},
+		{
+			"planned_change",
+			`{"@level":"info","@message":"null_resource.foo: Plan to create","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","change":{"resource":{"addr":"null_resource.foo","module":"","resource":"null_resource.foo","implied_provider":"null","resource_type":"null_resource","resource_name":"foo","resource_key":null},"action":"create"},"type":"planned_change"}`,
+			&PlannedChangeEvent{
+				EventHeader: header(EventTypePlannedChange, "info", "null_resource.foo: Plan to create"),
+				Change: ResourceInstanceChange{
+					Resource: nullFoo,
+					Action:   ChangeActionCreate,
+				},
+			},
+		},
+		{
+			"change_summary",
+			`{"@level":"info","@message":"Plan: 1 to add, 0 to change, 0 to destroy.","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","changes":{"add":1,"change":0,"import":0,"remove":0,"operation":"plan"},"type":"change_summary"}`,
+			&ChangeSummaryEvent{
+				EventHeader: header(EventTypeChangeSummary, "info", "Plan: 1 to add, 0 to change, 0 to destroy."),
+				Changes: ChangeSummary{
+					Add:       1,
+					Operation: "plan",
+				},
+			},
+		},
+		{
+			"outputs",
+			`{"@level":"info","@message":"Outputs: 1","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","outputs":{"foo":{"sensitive":false,"type":"string","value":"bar"}},"type":"outputs"}`,
+			&OutputsEvent{
+				EventHeader: header(EventTypeOutputs, "info", "Outputs: 1"),
+				Outputs: map[string]OutputChange{
+					"foo": {
+						Type:  json.RawMessage(`"string"`),
+						Value: json.RawMessage(`"bar"`),
+					},
+				},
+			},
+		},
+		{
+			"apply_complete",
+			`{"@level":"info","@message":"null_resource.foo: Creation complete after 0s [id=123]","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","hook":{"resource":{"addr":"null_resource.foo","module":"","resource":"null_resource.foo","implied_provider":"null","resource_type":"null_resource","resource_name":"foo","resource_key":null},"action":"create","id_key":"id","id_value":"123","elapsed_seconds":0},"type":"apply_complete"}`,
+			&ApplyCompleteEvent{
+				EventHeader: header(EventTypeApplyComplete, "info", "null_resource.foo: Creation complete after 0s [id=123]"),
+				Hook: OperationHook{
+					Resource: nullFoo,
+					Action:   ChangeActionCreate,
+					IDKey:    "id",
+					IDValue:  "123",
+				},
+			},
+		},
+		{
+			"test_summary",
+			`{"@level":"info","@message":"Success! 2 passed, 0 failed.","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","test_summary":{"status":"pass","passed":2,"failed":0,"errored":0,"skipped":0},"type":"test_summary"}`,
+			&TestSummaryEvent{
+				EventHeader: header(EventTypeTestSummary, "info", "Success! 2 passed, 0 failed."),
+				Summary: TestSummaryCounts{
+					Status: TestStatusPass,
+					Passed: 2,
+				},
+			},
+		},
+		{
+			"test_run",
+			`{"@level":"info","@message":"  \"first\"... pass","@module":"tofu.ui","@testfile":"main.tftest.hcl","@testrun":"first","@timestamp":"2024-01-02T03:04:05Z","test_run":{"path":"main.tftest.hcl","run":"first","status":"pass"},"type":"test_run"}`,
+			&TestRunEvent{
+				EventHeader: func() EventHeader {
+					h := header(EventTypeTestRun, "info", `  "first"... pass`)
+					h.TestFile = "main.tftest.hcl"
+					h.TestRun = "first"
+					return h
+				}(),
+				Run: TestRunStatus{
+					Path:   "main.tftest.hcl",
+					Run:    "first",
+					Status: TestStatusPass,
+				},
+			},
+		},
+		{
+			"unknown",
+			`{"@level":"info","@message":"something new","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","type":"from_the_future","future":true}`,
+			&UnknownEvent{
+				EventHeader: header("from_the_future", "info", "something new"),
+				Raw:         json.RawMessage(`{"@level":"info","@message":"something new","@module":"tofu.ui","@timestamp":"2024-01-02T03:04:05Z","type":"from_the_future","future":true}`),
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := ParseEvent([]byte(c.line))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseEvent_invalid(t *testing.T) {
+	_, err := ParseEvent([]byte("not json"))
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func TestEventWriter(t *testing.T) {
+	var types []EventType
+	w := newEventWriter(func(ev Event) {
+		types = append(types, ev.Header().Type)
+	})
+
+	for _, line := range []string{
+		`{"@level":"info","@message":"OpenTofu 1.10.5","type":"version","tofu":"1.10.5","ui":"1.2"}` + "\n",
+		"\n",
+		"not json\n",
+		`{"@level":"info","@message":"Plan: 0 to add, 0 to change, 0 to destroy.","type":"change_summary","changes":{"operation":"plan"}}`,
+	} {
+		n, err := w.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(line) {
+			t.Fatalf("expected %d bytes written, got %d", len(line), n)
+		}
+	}
+
+	expected := []EventType{EventTypeVersion, EventTypeChangeSummary}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
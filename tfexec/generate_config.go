@@ -0,0 +1,139 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImportBlock represents an import block of the OpenTofu configuration.
+type ImportBlock struct {
+	// To is the address of the resource instance to import into, such as
+	// module.app.aws_instance.web["a"].
+	To string
+
+	// ID is the import ID of the existing infrastructure object, as
+	// documented by the provider of the resource type.
+	ID string
+
+	// Provider optionally references a provider configuration, such as
+	// aws.west. If empty, the default provider configuration is used.
+	Provider string
+}
+
+const (
+	addressIdent = `[a-zA-Z_][a-zA-Z0-9_-]*`
+	addressKey   = `\[(?:[0-9]+|"[^"\\$%\x00-\x1f]*")\]`
+)
+
+var (
+	importToRegexp = regexp.MustCompile(`^(?:module\.` + addressIdent + `(?:` + addressKey + `)?\.)*` +
+		`(?:data\.)?` + addressIdent + `\.` + addressIdent + `(?:` + addressKey + `)?$`)
+	importProviderRegexp = regexp.MustCompile(`^` + addressIdent + `(?:\.` + addressIdent + `)?$`)
+)
+
+// GenerateImportConfig generates configuration for existing infrastructure
+// objects.
+//
+// It writes the given import blocks to a temporary file in the working
+// directory, runs `tofu plan` with the -generate-config-out flag and returns
+// the generated configuration. The temporary files are removed afterwards.
+//
+// The import blocks must target resources which have no configuration yet.
+// The given options are passed to the plan, for example to set variables.
+//
+// If the plan fails after generating configuration, for example because a
+// generated argument is invalid, both the configuration and the error are
+// returned so the configuration can be corrected by hand.
+func (tf *Tofu) GenerateImportConfig(ctx context.Context, imports []ImportBlock, opts ...PlanOption) (string, error) {
+	if len(imports) == 0 {
+		return "", fmt.Errorf("at least one import block is required")
+	}
+
+	importsFile, err := renderImportBlocks(imports)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(tf.workingDir, "tfexec-imports-*.tf")
+	if err != nil {
+		return "", fmt.Errorf("unable to create imports file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(importsFile)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to write imports file: %w", err)
+	}
+
+	// the generated config must not be written to the working directory,
+	// where it would become part of the configuration of subsequent runs
+	outDir, err := os.MkdirTemp("", "tfexec-generated")
+	if err != nil {
+		return "", fmt.Errorf("unable to create directory for generated config: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	outPath := filepath.Join(outDir, "generated.tf")
+	opts = append(opts, GenerateConfigOut(outPath))
+
+	_, planErr := tf.Plan(ctx, opts...)
+
+	generated, err := os.ReadFile(outPath)
+	if err != nil {
+		if planErr != nil {
+			return "", planErr
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("no configuration was generated, the import targets may already have configuration")
+		}
+		return "", fmt.Errorf("unable to read generated config: %w", err)
+	}
+
+	return string(generated), planErr
+}
+
+func renderImportBlocks(imports []ImportBlock) (string, error) {
+	var b strings.Builder
+
+	for i, imp := range imports {
+		if imp.To == "" {
+			return "", fmt.Errorf("import block %d: to cannot be blank", i)
+		}
+		if !importToRegexp.MatchString(imp.To) {
+			return "", fmt.Errorf("import block %d: invalid resource instance address %q", i, imp.To)
+		}
+		if imp.ID == "" {
+			return "", fmt.Errorf("import block %d: id cannot be blank", i)
+		}
+		if imp.Provider != "" && !importProviderRegexp.MatchString(imp.Provider) {
+			return "", fmt.Errorf("import block %d: invalid provider configuration address %q", i, imp.Provider)
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("import {\n")
+		fmt.Fprintf(&b, "  to = %s\n", imp.To)
+		fmt.Fprintf(&b, "  id = %s\n", quoteHCLString(imp.ID))
+		if imp.Provider != "" {
+			fmt.Fprintf(&b, "  provider = %s\n", imp.Provider)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"testing"
+)
+
+func TestRenderImportBlocks(t *testing.T) {
+	actual, err := renderImportBlocks([]ImportBlock{
+		{
+			To: "random_string.foo",
+			ID: "abc",
+		},
+		{
+			To: `module.app[0].random_string.foo`,
+			ID: "def",
+		},
+		{
+			To:       `aws_instance.bar["web"]`,
+			ID:       "i-${123}",
+			Provider: "aws.west",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `import {
+  to = random_string.foo
+  id = "abc"
+}
+
+import {
+  to = module.app[0].random_string.foo
+  id = "def"
+}
+
+import {
+  to = aws_instance.bar["web"]
+  id = "i-$${123}"
+  provider = aws.west
+}
+`
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+
+	_, err = renderImportBlocks([]ImportBlock{{ID: "abc"}})
+	if err == nil {
+		t.Fatal("expected error for blank to, got none")
+	}
+}
+
+func TestRenderImportBlocks_invalidAddress(t *testing.T) {
+	for _, imp := range []ImportBlock{
+		{To: "random_string", ID: "abc"},
+		{To: "random_string.foo\n}\nresource \"null_resource\" \"bar\" {", ID: "abc"},
+		{To: `random_string.foo["${file("/etc/passwd")}"]`, ID: "abc"},
+		{To: "random_string.foo[1", ID: "abc"},
+		{To: "random_string.foo", ID: "abc", Provider: "aws.west\n}\nimport {"},
+		{To: "random_string.foo", ID: "abc", Provider: "aws.west.east"},
+	} {
+		_, err := renderImportBlocks([]ImportBlock{imp})
+		if err == nil {
+			t.Fatalf("expected error for %+v, got none", imp)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"io"
+	"os/exec"
+)
+
+// Command is an OpenTofu command passed through the hooks added with AddHook.
+type Command struct {
+	// RunID identifies the command in the log and in RunResult.
+	RunID string
+
+	// Cmd is the process running the command. Before calling the next
+	// RunFunc, a hook may modify its Args, whose first element is the
+	// executable, and its Env. Afterwards, ProcessState is set if the process
+	// was started.
+	Cmd *exec.Cmd
+}
+
+// AddStdout streams the stdout of the command to w, in addition to any other
+// writers. It must be called before calling the next RunFunc.
+func (c *Command) AddStdout(w io.Writer) {
+	c.Cmd.Stdout = mergeWriters(c.Cmd.Stdout, w)
+}
+
+// AddStderr streams the stderr of the command to w, in addition to any other
+// writers. It must be called before calling the next RunFunc.
+func (c *Command) AddStderr(w io.Writer) {
+	c.Cmd.Stderr = mergeWriters(c.Cmd.Stderr, w)
+}
+
+// RunFunc runs a command.
+type RunFunc func(ctx context.Context, c *Command) error
+
+// Hook wraps running OpenTofu commands, like HTTP middleware, for example to
+// record metrics, acquire a lock or inject short-lived credentials into the
+// environment:
+//
+//	tf.AddHook(func(next tfexec.RunFunc) tfexec.RunFunc {
+//		return func(ctx context.Context, c *tfexec.Command) error {
+//			c.Cmd.Env = append(c.Cmd.Env, "AWS_SESSION_TOKEN="+token())
+//			return next(ctx, c)
+//		}
+//	})
+//
+// A hook calls next to run the command and returns its error, possibly
+// wrapped, or returns an error without calling next to prevent the command
+// from running.
+type Hook func(next RunFunc) RunFunc
+
+// AddHook adds hooks wrapping every command, including Version and
+// FormatString. Hooks added first are outermost, so they see the changes
+// made to the command by hooks added later only after calling next.
+func (tf *Tofu) AddHook(hooks ...Hook) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.hooks = append(tf.hooks, hooks...)
+}
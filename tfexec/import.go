@@ -22,6 +22,7 @@ type importConfig struct {
 	reattachInfo       ReattachInfo
 	state              string
 	stateOut           string
+	typedVars          []*VarValueOption
 	vars               []string
 	varFiles           []string
 }
@@ -69,6 +70,10 @@ func (opt *StateOutOption) configureImport(conf *importConfig) {
 	conf.stateOut = opt.path
 }
 
+func (opt *VarValueOption) configureImport(conf *importConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
 func (opt *VarOption) configureImport(conf *importConfig) {
 	conf.vars = append(conf.vars, opt.assignment)
 }
@@ -129,11 +134,16 @@ func (tf *Tofu) importCmd(ctx context.Context, address, id string, opts ...Impor
 			args = append(args, "-var", v)
 		}
 	}
+	args = append(args, typedVarArgs(c.typedVars)...)
 
 	// required args, always pass
 	args = append(args, address, id)
 
 	mergeEnv := map[string]string{}
+	err := addTypedVarEnv(mergeEnv, c.typedVars)
+	if err != nil {
+		return nil, err
+	}
 	if c.reattachInfo != nil {
 		reattachStr, err := c.reattachInfo.marshalString()
 		if err != nil {
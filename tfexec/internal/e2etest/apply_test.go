@@ -64,3 +64,26 @@ func TestApplyDestroy(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyEvents(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		var completed []string
+		err = tf.ApplyEvents(context.Background(), func(ev tfexec.Event) {
+			if ev, ok := ev.(*tfexec.ApplyCompleteEvent); ok {
+				completed = append(completed, ev.Hook.Resource.Addr)
+			}
+		})
+		if err != nil {
+			t.Fatalf("error running ApplyEvents: %s", err)
+		}
+
+		if len(completed) != 1 || completed[0] != "null_resource.foo" {
+			t.Fatalf("expected apply_complete for null_resource.foo, got: %v", completed)
+		}
+	})
+}
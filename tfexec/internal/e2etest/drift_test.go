@@ -0,0 +1,64 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+func TestDetectDrift(t *testing.T) {
+	runTest(t, "drift", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		err = tf.Apply(context.Background())
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+
+		report, err := tf.DetectDrift(context.Background())
+		if err != nil {
+			t.Fatalf("error running DetectDrift: %s", err)
+		}
+		if report.HasDrift() {
+			t.Fatalf("expected no drift, got %d drifted resources", len(report.Resources))
+		}
+
+		err = os.Remove(filepath.Join(tf.WorkingDir(), "foo.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err = tf.DetectDrift(context.Background())
+		if err != nil {
+			t.Fatalf("error running DetectDrift: %s", err)
+		}
+		if len(report.Resources) != 1 {
+			t.Fatalf("expected 1 drifted resource, got %d", len(report.Resources))
+		}
+
+		dr := report.Resources[0]
+		if dr.Address != "local_file.foo" {
+			t.Fatalf("expected drift of local_file.foo, got %q", dr.Address)
+		}
+		if !dr.Deleted {
+			t.Fatal("expected resource to be reported as deleted")
+		}
+		if dr.PlannedAction != planquery.ActionCreate || !dr.Reverted {
+			t.Fatalf("expected drift to be reverted by creating the resource, got %q (reverted: %t)", dr.PlannedAction, dr.Reverted)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestEncryption(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		if tfv.LessThan(version.Must(version.NewVersion("1.7.0"))) {
+			t.Skip("state encryption was added in OpenTofu 1.7.0, so test is not valid")
+		}
+
+		passphrase := tfexec.EncryptionKeyProvider{
+			Type:   "pbkdf2",
+			Name:   "mykey",
+			Config: map[string]interface{}{"passphrase": "correct-horse-battery-staple"},
+		}
+		aesGCM := tfexec.EncryptionMethod{
+			Type: "aes_gcm",
+			Name: "mymethod",
+			Keys: passphrase.Ref(),
+		}
+		err := tf.SetEncryption(&tfexec.EncryptionConfig{
+			KeyProviders: []tfexec.EncryptionKeyProvider{passphrase},
+			Methods:      []tfexec.EncryptionMethod{aesGCM},
+			State:        &tfexec.EncryptionTarget{Method: aesGCM.Ref(), Enforced: true},
+			Plan:         &tfexec.EncryptionTarget{Method: aesGCM.Ref(), Enforced: true},
+		})
+		if err != nil {
+			t.Fatalf("error setting encryption: %s", err)
+		}
+
+		err = tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		planPath := filepath.Join(tf.WorkingDir(), "tfplan")
+		_, err = tf.Plan(context.Background(), tfexec.Out(planPath))
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		plan, err := tf.ShowPlanFile(context.Background(), planPath)
+		if err != nil {
+			t.Fatalf("error reading encrypted plan: %s", err)
+		}
+		if len(plan.ResourceChanges) != 1 {
+			t.Fatalf("expected 1 resource change, got %d", len(plan.ResourceChanges))
+		}
+
+		err = tf.Apply(context.Background(), tfexec.DirOrPlan(planPath))
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+
+		statePath := filepath.Join(tf.WorkingDir(), "terraform.tfstate")
+		raw, err := os.ReadFile(statePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(raw), "encrypted_data") {
+			t.Fatalf("expected state file to be encrypted, got:\n%s", raw)
+		}
+
+		state, err := tf.ShowStateFile(context.Background(), statePath)
+		if err != nil {
+			t.Fatalf("error reading encrypted state: %s", err)
+		}
+		if len(state.Values.RootModule.Resources) != 1 {
+			t.Fatalf("expected 1 resource, got %d", len(state.Values.RootModule.Resources))
+		}
+	})
+}
@@ -12,6 +12,7 @@ package e2etest
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -71,6 +72,16 @@ func TestMissingVar(t *testing.T) {
 			t.Fatalf("expected exec.ExitError, got %T, %s", err, err)
 		}
 
+		// only the JSON diagnostics of plan are classified
+		_, err = tf.PlanJSON(context.Background(), io.Discard, tfexec.Var(shortVarName+"=foo"))
+		var varErr *tfexec.MissingVarError
+		if !errors.As(err, &varErr) {
+			t.Fatalf("expected MissingVarError, got %T, %s", err, err)
+		}
+		if varErr.VariableName != longVarName {
+			t.Fatalf("expected missing variable %q, got %q", longVarName, varErr.VariableName)
+		}
+
 		// Test for no error when all variables have a value
 		_, err = tf.Plan(context.Background(), tfexec.Var(shortVarName+"=foo"), tfexec.Var(longVarName+"=foo"))
 		if err != nil {
@@ -114,6 +125,20 @@ func TestLockedState(t *testing.T) {
 		if !strings.Contains(err.Error(), "state lock") {
 			t.Fatal("expected err.Error() to contain 'state lock', but it did not")
 		}
+
+		// only the JSON diagnostics of apply are classified
+		err = tf.ApplyJSON(context.Background(), io.Discard)
+		if !errors.Is(err, tfexec.ErrStateLocked) {
+			t.Fatalf("expected ErrStateLocked, got %T, %s", err, err)
+		}
+
+		var lockErr *tfexec.StateLockedError
+		if !errors.As(err, &lockErr) {
+			t.Fatalf("expected StateLockedError, got %T, %s", err, err)
+		}
+		if lockErr.LockInfo.ID != "2b6a6738-5dd5-50d6-c0ae-f6352977666b" {
+			t.Fatalf("unexpected lock ID %q", lockErr.LockInfo.ID)
+		}
 	})
 }
 
@@ -184,6 +209,39 @@ func TestContext_sleepTimeoutExpired(t *testing.T) {
 	})
 }
 
+func TestContext_sleepTimeoutGraceful(t *testing.T) {
+	runTest(t, "sleep", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("err during init: %s", err)
+		}
+
+		tf.SetCancelGracePeriod(30 * time.Second)
+
+		ctx := context.Background()
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		err = tf.Apply(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %T %s", err, err)
+		}
+		if stage := tfexec.CancelStageOf(err); stage != tfexec.CancelStageInterrupt {
+			t.Fatalf("expected cancel stage %q, got %q", tfexec.CancelStageInterrupt, stage)
+		}
+		if elapsed := time.Since(start); elapsed > 30*time.Second {
+			t.Fatalf("tofu apply should have stopped gracefully before the grace period expired, took %s", elapsed)
+		}
+
+		// the interrupted apply must have released the state lock
+		_, err = tf.Plan(context.Background(), tfexec.LockTimeout("0s"))
+		if err != nil {
+			t.Fatalf("error running Plan after interrupted Apply: %s", err)
+		}
+	})
+}
+
 func TestContext_alreadyCancelled(t *testing.T) {
 	runTest(t, "", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -199,3 +257,46 @@ func TestContext_alreadyCancelled(t *testing.T) {
 		}
 	})
 }
+
+func TestDiagnosticsError(t *testing.T) {
+	runTest(t, "var", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("err during init: %s", err)
+		}
+
+		_, err = tf.PlanJSON(context.Background(), io.Discard)
+		if err == nil {
+			t.Fatalf("expected error running PlanJSON, none returned")
+		}
+
+		var diagErr *tfexec.DiagnosticsError
+		if !errors.As(err, &diagErr) {
+			t.Fatalf("expected DiagnosticsError, got %T, %s", err, err)
+		}
+		if len(diagErr.Diagnostics) == 0 {
+			t.Fatal("expected diagnostics, got none")
+		}
+		if !errors.Is(err, tfexec.ErrMissingVar) {
+			t.Fatalf("expected ErrMissingVar, got %T, %s", err, err)
+		}
+
+		var ee *exec.ExitError
+		if !errors.As(err, &ee) {
+			t.Fatalf("expected exec.ExitError, got %T, %s", err, err)
+		}
+	})
+}
+
+func TestNoInit(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		_, err := tf.PlanJSON(context.Background(), io.Discard)
+		if err == nil {
+			t.Fatal("expected error running PlanJSON without Init, none returned")
+		}
+
+		if !errors.Is(err, tfexec.ErrNoInit) {
+			t.Fatalf("expected ErrNoInit, got %T, %s", err, err)
+		}
+	})
+}
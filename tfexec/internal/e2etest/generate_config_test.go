@@ -0,0 +1,51 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestGenerateImportConfig(t *testing.T) {
+	runTest(t, "import_generate_config", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		ctx := context.Background()
+
+		err := tf.Init(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hcl, err := tf.GenerateImportConfig(ctx, []tfexec.ImportBlock{{
+			To: "random_string.imported",
+			ID: "asdlfjksdlfkjsdlfk",
+		}})
+		if err != nil {
+			t.Fatalf("error generating import config: %s", err)
+		}
+
+		if !strings.Contains(hcl, `resource "random_string" "imported"`) {
+			t.Fatalf("expected generated config for random_string.imported, got:\n%s", hcl)
+		}
+
+		// only the original configuration is left behind
+		entries, err := os.ReadDir(tf.WorkingDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tf") && e.Name() != "main.tf" {
+				t.Fatalf("unexpected file %q left in working directory", e.Name())
+			}
+		}
+	})
+}
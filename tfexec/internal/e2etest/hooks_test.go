@@ -0,0 +1,47 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestAddHook(t *testing.T) {
+	runTest(t, "", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		var subcommands []string
+		var exitCodes []int
+		tf.AddHook(func(next tfexec.RunFunc) tfexec.RunFunc {
+			return func(ctx context.Context, c *tfexec.Command) error {
+				subcommands = append(subcommands, c.Cmd.Args[1])
+				err := next(ctx, c)
+				exitCodes = append(exitCodes, c.Cmd.ProcessState.ExitCode())
+				return err
+			}
+		})
+
+		_, _, err := tf.Version(context.Background(), true)
+		if err != nil {
+			t.Fatalf("error running Version: %s", err)
+		}
+
+		_, err = tf.FormatString(context.Background(), "a    = 1\n")
+		if err != nil {
+			t.Fatalf("error running FormatString: %s", err)
+		}
+
+		if len(subcommands) != 2 || subcommands[0] != "version" || subcommands[1] != "fmt" {
+			t.Fatalf("expected hooks to run for version and fmt, got %q", subcommands)
+		}
+		if exitCodes[0] != 0 || exitCodes[1] != 0 {
+			t.Fatalf("expected exit codes 0, got %v", exitCodes)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestSetSlogLogger(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		err = tf.WorkspaceNew(context.Background(), "logged")
+		if err != nil {
+			t.Fatalf("error creating workspace: %s", err)
+		}
+
+		var logs bytes.Buffer
+		tf.SetSlogLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+		_, err = tf.Plan(context.Background())
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		var start, finish struct {
+			Subcommand string `json:"subcommand"`
+			Workspace  string `json:"workspace"`
+			ExitCode   int    `json:"exit_code"`
+		}
+		dec := json.NewDecoder(&logs)
+		if err := dec.Decode(&start); err != nil {
+			t.Fatal(err)
+		}
+		if err := dec.Decode(&finish); err != nil {
+			t.Fatal(err)
+		}
+
+		if start.Subcommand != "plan" || start.Workspace != "logged" {
+			t.Fatalf("unexpected start record: %+v", start)
+		}
+		if finish.ExitCode != 0 && finish.ExitCode != 2 {
+			t.Fatalf("unexpected exit code in finish record: %+v", finish)
+		}
+	})
+}
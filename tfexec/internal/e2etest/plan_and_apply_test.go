@@ -0,0 +1,67 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		var planFile string
+		reject := tfexec.ApproverFunc(func(ctx context.Context, plan *tfexec.PlanResult) (tfexec.ApprovalDecision, error) {
+			planFile = plan.PlanFile
+			if plan.Add != 1 {
+				t.Errorf("expected 1 resource to add, got %d", plan.Add)
+			}
+			return tfexec.Reject("not today"), nil
+		})
+
+		applied, err := tf.PlanAndApply(context.Background(), reject)
+		var rejectedErr *tfexec.PlanRejectedError
+		if !errors.As(err, &rejectedErr) {
+			t.Fatalf("expected PlanRejectedError, got %v", err)
+		}
+		if applied || rejectedErr.Reason != "not today" {
+			t.Fatalf("unexpected result: applied %t, reason %q", applied, rejectedErr.Reason)
+		}
+		if _, err := os.Stat(planFile); !os.IsNotExist(err) {
+			t.Fatalf("expected plan file to be removed, got %v", err)
+		}
+
+		approve := tfexec.ApproverFunc(func(ctx context.Context, plan *tfexec.PlanResult) (tfexec.ApprovalDecision, error) {
+			return tfexec.Approve("looks good"), nil
+		})
+
+		applied, err = tf.PlanAndApply(context.Background(), approve)
+		if err != nil {
+			t.Fatalf("error running PlanAndApply: %s", err)
+		}
+		if !applied {
+			t.Fatal("expected plan to be applied")
+		}
+
+		applied, err = tf.PlanAndApply(context.Background(), reject)
+		if err != nil {
+			t.Fatalf("expected no approval to be needed without changes, got %s", err)
+		}
+		if applied {
+			t.Fatal("expected nothing to be applied without changes")
+		}
+	})
+}
@@ -6,8 +6,11 @@
 package e2etest
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/hashicorp/go-version"
@@ -34,6 +37,35 @@ func TestPlan(t *testing.T) {
 
 }
 
+func TestPlan_withResult(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		var result tfexec.RunResult
+		_, err = tf.Plan(tfexec.WithResult(context.Background(), &result))
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		// -detailed-exitcode reports changes with exit code 2
+		if result.ExitCode != 2 {
+			t.Fatalf("expected exit code 2, got %d", result.ExitCode)
+		}
+		if result.Args[0] != "plan" {
+			t.Fatalf("expected plan command, got %q", result.Args)
+		}
+		if !bytes.Contains(result.Stdout, []byte("Plan:")) {
+			t.Fatalf("expected plan output, got %q", result.Stdout)
+		}
+		if result.Duration <= 0 {
+			t.Fatalf("expected positive duration, got %s", result.Duration)
+		}
+	})
+}
+
 func TestPlanWithState(t *testing.T) {
 	runTest(t, "basic_with_state", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
 		err := tf.Init(context.Background())
@@ -69,3 +101,136 @@ func TestPlanJSON(t *testing.T) {
 		}
 	})
 }
+
+func TestPlanJSON_writerNotRetained(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		var buf bytes.Buffer
+		_, err = tf.PlanJSON(context.Background(), &buf)
+		if err != nil {
+			t.Fatalf("error running PlanJSON: %s", err)
+		}
+		if buf.Len() == 0 {
+			t.Fatal("expected PlanJSON output, got none")
+		}
+
+		n := buf.Len()
+		_, err = tf.Plan(context.Background())
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+		if buf.Len() != n {
+			t.Fatalf("expected PlanJSON writer to be detached after the call, got %d more bytes", buf.Len()-n)
+		}
+	})
+}
+
+func TestPlanEvents(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		var planned []string
+		var summary *tfexec.ChangeSummaryEvent
+		hasChanges, err := tf.PlanEvents(context.Background(), func(ev tfexec.Event) {
+			switch ev := ev.(type) {
+			case *tfexec.PlannedChangeEvent:
+				planned = append(planned, ev.Change.Resource.Addr)
+			case *tfexec.ChangeSummaryEvent:
+				summary = ev
+			}
+		})
+		if err != nil {
+			t.Fatalf("error running PlanEvents: %s", err)
+		}
+		if !hasChanges {
+			t.Fatalf("expected: true, got: %t", hasChanges)
+		}
+
+		if len(planned) != 1 || planned[0] != "null_resource.foo" {
+			t.Fatalf("expected planned change for null_resource.foo, got: %v", planned)
+		}
+		if summary == nil {
+			t.Fatal("expected change_summary event, got none")
+		}
+		if summary.Changes.Add != 1 {
+			t.Fatalf("expected 1 to add, got %d", summary.Changes.Add)
+		}
+	})
+}
+
+func TestPlanExclude(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		hasChanges, err := tf.Plan(context.Background(), tfexec.Exclude("null_resource.foo"))
+		if tfv.LessThan(version.Must(version.NewVersion("1.9.0"))) {
+			var mismatchErr *tfexec.ErrVersionMismatch
+			if !errors.As(err, &mismatchErr) {
+				t.Fatalf("expected ErrVersionMismatch, got %T %s", err, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+		if hasChanges {
+			t.Fatalf("expected: false, got: %t", hasChanges)
+		}
+	})
+}
+
+func TestPlanDetailed(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		result, err := tf.PlanDetailed(context.Background())
+		if err != nil {
+			t.Fatalf("error running PlanDetailed: %s", err)
+		}
+		defer result.Close()
+
+		if !result.HasChanges {
+			t.Fatal("expected changes, got none")
+		}
+		if result.Add != 1 || result.Change != 0 || result.Destroy != 0 {
+			t.Fatalf("expected 1 to add, got %d to add, %d to change, %d to destroy", result.Add, result.Change, result.Destroy)
+		}
+		if result.Plan == nil || len(result.Plan.ResourceChanges) != 1 {
+			t.Fatalf("expected parsed plan with 1 resource change, got %#v", result.Plan)
+		}
+
+		err = tf.Apply(context.Background(), result)
+		if err != nil {
+			t.Fatalf("error applying saved plan: %s", err)
+		}
+
+		state, err := tf.Show(context.Background())
+		if err != nil {
+			t.Fatalf("error running Show: %s", err)
+		}
+		if state.Values == nil || state.Values.RootModule == nil || len(state.Values.RootModule.Resources) != 1 {
+			t.Fatal("expected 1 resource in state after applying the saved plan")
+		}
+
+		planFile := result.PlanFile
+		if err := result.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(planFile); !os.IsNotExist(err) {
+			t.Fatalf("expected plan file to be removed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestApplyPolicies(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		err = tf.Apply(context.Background())
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+
+		_, err = tf.Plan(context.Background(), tfexec.Destroy(true), tfexec.Out("destroy.tfplan"))
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		err = tf.Apply(context.Background(), tfexec.DirOrPlan("destroy.tfplan"), tfexec.Policies(tfexec.MaxDeletions(0)))
+		var violationErr *tfexec.PolicyViolationError
+		if !errors.As(err, &violationErr) {
+			t.Fatalf("expected PolicyViolationError, got %T: %v", err, err)
+		}
+		if len(violationErr.Violations) != 1 || violationErr.Violations[0].Policy != "max-deletions" || violationErr.Violations[0].Address != "null_resource.foo" {
+			t.Fatalf("unexpected violations: %#v", violationErr.Violations)
+		}
+
+		err = tf.Apply(context.Background(), tfexec.DirOrPlan("destroy.tfplan"), tfexec.Policies(tfexec.InWorkspaces(tfexec.DenyDestroy(), "prod")))
+		if err != nil {
+			t.Fatalf("error applying plan: %s", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestPlanProvenance(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		_, err = tf.Plan(context.Background(), tfexec.Out("stale.tfplan"), tfexec.Provenance(true))
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		p, err := tf.ReadPlanProvenance("stale.tfplan")
+		if err != nil {
+			t.Fatalf("error reading plan provenance: %s", err)
+		}
+		if p.Workspace != "default" || p.StateSerial != 0 || p.PlanChecksum == "" {
+			t.Fatalf("unexpected plan provenance: %#v", p)
+		}
+
+		// move the state on, making the saved plan stale
+		err = tf.Apply(context.Background())
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+
+		err = tf.Apply(context.Background(), tfexec.DirOrPlan("stale.tfplan"), tfexec.Provenance(true))
+		if !errors.Is(err, tfexec.ErrStalePlan) {
+			t.Fatalf("expected ErrStalePlan, got %v", err)
+		}
+
+		_, err = tf.Plan(context.Background(), tfexec.Destroy(true), tfexec.Out("fresh.tfplan"), tfexec.Provenance(true))
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+
+		err = tf.Apply(context.Background(), tfexec.DirOrPlan("fresh.tfplan"), tfexec.Provenance(true))
+		if err != nil {
+			t.Fatalf("error applying fresh plan: %s", err)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestReadOnly(t *testing.T) {
+	runTest(t, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		tf.SetReadOnly(true)
+
+		hasChanges, err := tf.Plan(context.Background())
+		if err != nil {
+			t.Fatalf("error running Plan: %s", err)
+		}
+		if !hasChanges {
+			t.Fatal("expected changes")
+		}
+
+		err = tf.Apply(context.Background())
+		if !errors.Is(err, tfexec.ErrReadOnly) {
+			t.Fatalf("expected ErrReadOnly, got %v", err)
+		}
+
+		err = tf.WorkspaceNew(context.Background(), "preview")
+		if !errors.Is(err, tfexec.ErrReadOnly) {
+			t.Fatalf("expected ErrReadOnly, got %v", err)
+		}
+
+		tf.SetReadOnly(false)
+
+		err = tf.Apply(context.Background())
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestStateList(t *testing.T) {
+	runTest(t, "basic_with_state", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		addresses, err := tf.StateList(context.Background())
+		if err != nil {
+			t.Fatalf("error running StateList: %s", err)
+		}
+		if diff := cmp.Diff([]string{"null_resource.foo"}, addresses); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		addresses, err = tf.StateList(context.Background(), tfexec.ID("5510719323588825107"))
+		if err != nil {
+			t.Fatalf("error running StateList with ID: %s", err)
+		}
+		if diff := cmp.Diff([]string{"null_resource.foo"}, addresses); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		addresses, err = tf.StateList(context.Background(), tfexec.Address("null_resource.bar"))
+		if err != nil {
+			t.Fatalf("error running StateList with address: %s", err)
+		}
+		if len(addresses) != 0 {
+			t.Fatalf("expected no addresses, got %v", addresses)
+		}
+	})
+}
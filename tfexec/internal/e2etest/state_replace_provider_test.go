@@ -0,0 +1,54 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestStateReplaceProvider(t *testing.T) {
+	runTest(t, "basic_with_state", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		const (
+			fromProvider = "registry.opentofu.org/hashicorp/null"
+			toProvider   = "registry.opentofu.org/opentofu/null"
+		)
+
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		err = tf.StateReplaceProvider(context.Background(), fromProvider, toProvider)
+		if err != nil {
+			t.Fatalf("error running StateReplaceProvider: %s", err)
+		}
+
+		// the replacement provider must be installed to read the state
+		err = tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init after StateReplaceProvider: %s", err)
+		}
+
+		state, err := tf.ShowStateFile(context.Background(), filepath.Join(tf.WorkingDir(), "terraform.tfstate"))
+		if err != nil {
+			t.Fatalf("error running ShowStateFile: %s", err)
+		}
+
+		resources := state.Values.RootModule.Resources
+		if len(resources) != 1 {
+			t.Fatalf("expected 1 resource, got %d", len(resources))
+		}
+		if resources[0].ProviderName != toProvider {
+			t.Fatalf("expected provider %q, got %q", toProvider, resources[0].ProviderName)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestStateShow(t *testing.T) {
+	runTest(t, "basic_with_state", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		resource, err := tf.StateShow(context.Background(), "null_resource.foo")
+		if err != nil {
+			t.Fatalf("error running StateShow: %s", err)
+		}
+
+		if resource.Type != "null_resource" || resource.Name != "foo" || resource.Mode != tfjson.ManagedResourceMode {
+			t.Fatalf("unexpected resource %#v", resource)
+		}
+		if resource.AttributeValues["id"] != "5510719323588825107" {
+			t.Fatalf("unexpected id %v", resource.AttributeValues["id"])
+		}
+
+		_, err = tf.StateShow(context.Background(), "null_resource.bar")
+		if err == nil {
+			t.Fatal("expected error for missing resource, got none")
+		}
+	})
+}
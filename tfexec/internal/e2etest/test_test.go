@@ -42,3 +42,66 @@ func TestTestError(t *testing.T) {
 		}
 	})
 }
+
+func TestTestEvents(t *testing.T) {
+	runTest(t, "test_command_passing", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		var summary *tfexec.TestSummaryEvent
+		err := tf.TestEvents(context.Background(), func(ev tfexec.Event) {
+			if s, ok := ev.(*tfexec.TestSummaryEvent); ok {
+				summary = s
+			}
+		})
+		if err != nil {
+			t.Fatalf("error running test command: %s", err)
+		}
+
+		if summary == nil {
+			t.Fatal("expected test summary event, got none")
+		}
+		if summary.Summary.Status != tfexec.TestStatusPass {
+			t.Fatalf("expected status %q, got %q", tfexec.TestStatusPass, summary.Summary.Status)
+		}
+	})
+}
+
+func TestTestResults(t *testing.T) {
+	runTest(t, "test_command_passing", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		summary, err := tf.TestResults(context.Background(), nil, tfexec.Filter("tests/passthrough.tftest.hcl"))
+		if err != nil {
+			t.Fatalf("error running test command: %s", err)
+		}
+
+		if summary.Status != tfexec.TestStatusPass {
+			t.Fatalf("expected status %q, got %q", tfexec.TestStatusPass, summary.Status)
+		}
+		if len(summary.Files) != 1 {
+			t.Fatalf("expected 1 test file, got %d", len(summary.Files))
+		}
+		file := summary.Files[0]
+		if file.Path != "tests/passthrough.tftest.hcl" {
+			t.Fatalf("unexpected test file %q", file.Path)
+		}
+		if len(file.Runs) != 1 || file.Runs[0].Name != "variable_output_passthrough" || file.Runs[0].Status != tfexec.TestStatusPass {
+			t.Fatalf("unexpected runs: %#v", file.Runs)
+		}
+	})
+}
+
+func TestTestResultsError(t *testing.T) {
+	runTest(t, "test_command_failing", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		summary, err := tf.TestResults(context.Background(), nil)
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+
+		if summary == nil {
+			t.Fatal("expected test summary, got none")
+		}
+		if summary.Status != tfexec.TestStatusFail {
+			t.Fatalf("expected status %q, got %q", tfexec.TestStatusFail, summary.Status)
+		}
+		if summary.Failed != 1 {
+			t.Fatalf("expected 1 failed run, got %d", summary.Failed)
+		}
+	})
+}
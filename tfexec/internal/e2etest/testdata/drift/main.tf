@@ -0,0 +1,4 @@
+resource "local_file" "foo" {
+  content  = "foo"
+  filename = "${path.module}/foo.txt"
+}
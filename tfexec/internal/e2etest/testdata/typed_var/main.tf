@@ -0,0 +1,24 @@
+variable "tags" {
+  type = map(string)
+}
+
+variable "ports" {
+  type = list(number)
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+}
+
+output "tags" {
+  value = var.tags
+}
+
+output "ports" {
+  value = var.ports
+}
+
+output "password_length" {
+  value = length(var.password)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+)
+
+// staticTracer starts spans with random span IDs in a single trace.
+type staticTracer struct {
+	traceID [16]byte
+	spans   []*staticSpan
+}
+
+func (t *staticTracer) Start(ctx context.Context, name string, attrs ...tfexec.TraceAttribute) (context.Context, tfexec.Span) {
+	span := &staticSpan{sc: tfexec.SpanContext{TraceID: t.traceID, Sampled: true}}
+	_, _ = rand.Read(span.sc.SpanID[:])
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type staticSpan struct {
+	sc tfexec.SpanContext
+}
+
+func (s *staticSpan) SpanContext() tfexec.SpanContext        { return s.sc }
+func (s *staticSpan) SetAttributes(...tfexec.TraceAttribute) {}
+func (s *staticSpan) RecordError(error)                      {}
+func (s *staticSpan) End()                                   {}
+
+func TestSetTracer(t *testing.T) {
+	// OTLP collector stand-in recording the exported spans
+	var mu sync.Mutex
+	var exported bytes.Buffer
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		exported.Write(b)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	runTestWithVersions(t, []string{testutil.Latest_v1_10}, "basic", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		tracer := &staticTracer{}
+		_, _ = rand.Read(tracer.traceID[:])
+		tf.SetTracer(tracer)
+
+		err := tf.SetEnv(map[string]string{
+			"OTEL_TRACES_EXPORTER":        "otlp",
+			"OTEL_EXPORTER_OTLP_ENDPOINT": collector.URL,
+			"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+			"OTEL_EXPORTER_OTLP_INSECURE": "true",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// OTLP encodes trace and span IDs as raw bytes
+		if !bytes.Contains(exported.Bytes(), tracer.traceID[:]) {
+			t.Fatal("expected OpenTofu to export spans in the trace of the command span")
+		}
+		parent := tracer.spans[len(tracer.spans)-1].sc.SpanID
+		if !bytes.Contains(exported.Bytes(), parent[:]) {
+			t.Fatal("expected OpenTofu spans to be children of the command span")
+		}
+	})
+}
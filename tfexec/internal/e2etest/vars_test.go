@@ -0,0 +1,57 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package e2etest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+func TestApply_typedVars(t *testing.T) {
+	runTest(t, "typed_var", func(t *testing.T, tfv *version.Version, tf *tfexec.Tofu) {
+		err := tf.Init(context.Background())
+		if err != nil {
+			t.Fatalf("error running Init in test directory: %s", err)
+		}
+
+		err = tf.Apply(context.Background(),
+			tfexec.VarValue("tags", map[string]string{"env": "prod", "team": "platform", "name": "${var.password}"}),
+			tfexec.VarValue("ports", []int{80, 443}),
+			tfexec.SensitiveVar("password", "correct horse battery staple"),
+		)
+		if err != nil {
+			t.Fatalf("error running Apply: %s", err)
+		}
+
+		outputs, err := tf.Output(context.Background())
+		if err != nil {
+			t.Fatalf("error running Output: %s", err)
+		}
+
+		for name, expected := range map[string]string{
+			"tags":            `{"env":"prod","name":"${var.password}","team":"platform"}`,
+			"ports":           `[80,443]`,
+			"password_length": `28`,
+		} {
+			var actual any
+			if err := json.Unmarshal(outputs[name].Value, &actual); err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != expected {
+				t.Fatalf("expected output %s to be %s, got %s", name, expected, b)
+			}
+		}
+	})
+}
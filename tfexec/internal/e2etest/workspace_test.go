@@ -7,6 +7,7 @@ package e2etest
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -74,6 +75,14 @@ func TestWorkspace_already_exists(t *testing.T) {
 			if err == nil {
 				t.Fatalf("expected error, but did not get one")
 			}
+
+			var wsErr *tfexec.WorkspaceExistsError
+			if !errors.As(err, &wsErr) {
+				t.Fatalf("expected WorkspaceExistsError, got %T, %s", err, err)
+			}
+			if wsErr.Name != newWorkspace {
+				t.Fatalf("expected workspace %q, got %q", newWorkspace, wsErr.Name)
+			}
 		})
 	})
 }
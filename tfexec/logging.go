@@ -0,0 +1,222 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dataDirEnvVar = "TF_DATA_DIR"
+
+// SetSlogLogger specifies a structured logger for tfexec to use, replacing
+// any logger set with SetLogger.
+//
+// Every command logs a record when it starts and when it finishes, with the
+// attributes run_id, subcommand, working_dir, workspace and command, the
+// redacted command line. The finish record adds duration, exit_code and,
+// if the command failed, error. The run_id is also returned in
+// RunResult.RunID.
+func (tf *Tofu) SetSlogLogger(logger *slog.Logger) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.slogger = logger
+}
+
+// slogLogger returns the structured logger for a command. The caller must
+// hold tf.mu for reading.
+func (tf *Tofu) slogLogger() *slog.Logger {
+	if tf.slogger != nil {
+		return tf.slogger
+	}
+	return slog.New(&printfHandler{printfer: tf.logger})
+}
+
+// runLog logs the start and finish of a command.
+type runLog struct {
+	logger *slog.Logger
+	start  time.Time
+}
+
+// startRunLog logs the start of a command.
+func (tf *Tofu) startRunLog(ctx context.Context, runID string, cmd *exec.Cmd, redactor *redactor) *runLog {
+	tf.mu.RLock()
+	logger := tf.slogLogger()
+	tf.mu.RUnlock()
+
+	l := &runLog{
+		start: time.Now(),
+	}
+	l.logger = logger.With(
+		slog.String("run_id", runID),
+		slog.String("subcommand", subcommand(cmd.Args[1:])),
+		slog.String("working_dir", cmd.Dir),
+		slog.String("workspace", currentWorkspace(cmd.Dir, cmd.Env)),
+		slog.String("command", redactor.redactCmd(cmd)),
+	)
+
+	l.logger.InfoContext(ctx, "running Tofu command")
+	return l
+}
+
+// finish logs the finish of a command which returned err.
+func (l *runLog) finish(ctx context.Context, cmd *exec.Cmd, err error) {
+	attrs := []slog.Attr{
+		slog.Duration("duration", time.Since(l.start)),
+		slog.Int("exit_code", exitCode(cmd)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "finished Tofu command", attrs...)
+}
+
+func exitCode(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// subcommand returns the subcommand of the given arguments, such as "plan" or
+// "state rm".
+func subcommand(args []string) string {
+	var sub []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || len(sub) == 2 {
+			break
+		}
+		sub = append(sub, arg)
+	}
+	if len(sub) == 2 {
+		switch sub[0] {
+		case "metadata", "providers", "state", "workspace":
+		default:
+			sub = sub[:1]
+		}
+	}
+	return strings.Join(sub, " ")
+}
+
+// currentWorkspace returns the workspace selected in the working directory,
+// as recorded by OpenTofu in the data directory.
+func currentWorkspace(workingDir string, env []string) string {
+	dataDir := envMap(env)[dataDirEnvVar]
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(workingDir, dataDir)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dataDir, "environment"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "default"
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// printfHandler adapts a printfer, as passed to SetLogger, to slog. Records
+// are printed as "[LEVEL] message key=value ...".
+type printfHandler struct {
+	printfer printfer
+	attrs    []slog.Attr
+	groups   []string
+}
+
+func (h *printfHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.printfer != nil
+}
+
+func (h *printfHandler) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(r.Level.String())
+	b.WriteString("] ")
+	b.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writePrintfAttr(&b, "", a)
+	}
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writePrintfAttr(&b, prefix, a)
+		return true
+	})
+
+	h.printfer.Printf("%s", b.String())
+	return nil
+}
+
+func writePrintfAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writePrintfAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	v := a.Value.String()
+	if strings.ContainsAny(v, " \t\n\"=") || v == "" {
+		v = strconv.Quote(v)
+	}
+	b.WriteString(" ")
+	b.WriteString(prefix)
+	b.WriteString(a.Key)
+	b.WriteString("=")
+	b.WriteString(v)
+}
+
+func (h *printfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		a.Key = prefix + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+func (h *printfHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrintfHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(&printfHandler{printfer: log.New(&buf, "", 0)})
+
+	logger.With("run_id", "abc").WithGroup("tofu").Info("running Tofu command",
+		"subcommand", "state rm",
+		slog.Group("result", "exit_code", 0),
+		"empty", "",
+	)
+
+	expected := `[INFO] running Tofu command run_id=abc tofu.subcommand="state rm" tofu.result.exit_code=0 tofu.empty=""` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPrintfHandler_nil(t *testing.T) {
+	// a Tofu constructed without a logger must not panic
+	slog.New(&printfHandler{}).Info("running Tofu command")
+}
+
+func TestSubcommand(t *testing.T) {
+	for _, c := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"plan", "-no-color", "dir"}, "plan"},
+		{[]string{"state", "rm", "-no-color", "foo.bar"}, "state rm"},
+		{[]string{"workspace", "select", "-no-color", "dev"}, "workspace select"},
+		{[]string{"import", "-no-color", "foo.bar", "id"}, "import"},
+		{[]string{"import", "foo.bar", "id"}, "import"},
+		{[]string{"-c", "echo"}, ""},
+	} {
+		if actual := subcommand(c.args); actual != c.expected {
+			t.Errorf("expected subcommand %q for %q, got %q", c.expected, c.args, actual)
+		}
+	}
+}
+
+func TestCurrentWorkspace(t *testing.T) {
+	td := t.TempDir()
+
+	if ws := currentWorkspace(td, nil); ws != "default" {
+		t.Fatalf("expected default workspace, got %q", ws)
+	}
+
+	err := os.MkdirAll(filepath.Join(td, ".terraform"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(td, ".terraform", "environment"), []byte("staging"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws := currentWorkspace(td, nil); ws != "staging" {
+		t.Fatalf("expected staging workspace, got %q", ws)
+	}
+
+	err = os.MkdirAll(filepath.Join(td, "data"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(td, "data", "environment"), []byte("prod\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws := currentWorkspace(td, []string{"TF_DATA_DIR=data"}); ws != "prod" {
+		t.Fatalf("expected prod workspace, got %q", ws)
+	}
+}
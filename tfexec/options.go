@@ -9,6 +9,18 @@ import (
 	"encoding/json"
 )
 
+// AddressOption represents a resource address or address prefix positional
+// argument.
+type AddressOption struct {
+	address string
+}
+
+// Address represents a resource address or address prefix positional
+// argument.
+func Address(address string) *AddressOption {
+	return &AddressOption{address}
+}
+
 // AllowMissingConfigOption represents the -allow-missing-config flag.
 type AllowMissingConfigOption struct {
 	allowMissingConfig bool
@@ -141,6 +153,28 @@ func DryRun(dryRun bool) *DryRunOption {
 	return &DryRunOption{dryRun}
 }
 
+// ExcludeOption represents the -exclude flag.
+type ExcludeOption struct {
+	exclude string
+}
+
+// Exclude represents the -exclude flag, the inverse of Target. It requires
+// OpenTofu 1.9.0 or later and cannot be combined with Target.
+func Exclude(resource string) *ExcludeOption {
+	return &ExcludeOption{resource}
+}
+
+// FilterOption represents the -filter flag.
+type FilterOption struct {
+	path string
+}
+
+// Filter represents the -filter flag, restricting tofu test to the given
+// test file. It may be given multiple times.
+func Filter(path string) *FilterOption {
+	return &FilterOption{path}
+}
+
 type FSMirrorOption struct {
 	fsMirror string
 }
@@ -174,6 +208,18 @@ func FromModule(source string) *FromModuleOption {
 	return &FromModuleOption{source}
 }
 
+// GenerateConfigOutOption represents the -generate-config-out flag.
+type GenerateConfigOutOption struct {
+	path string
+}
+
+// GenerateConfigOut represents the -generate-config-out flag, which writes
+// configuration for resources in import blocks that have none to the given
+// path. The file must not exist yet.
+func GenerateConfigOut(path string) *GenerateConfigOutOption {
+	return &GenerateConfigOutOption{path}
+}
+
 type GetOption struct {
 	get bool
 }
@@ -190,6 +236,16 @@ func GetPlugins(getPlugins bool) *GetPluginsOption {
 	return &GetPluginsOption{getPlugins}
 }
 
+// IDOption represents the -id flag.
+type IDOption struct {
+	id string
+}
+
+// ID represents the -id flag.
+func ID(id string) *IDOption {
+	return &IDOption{id}
+}
+
 // LockOption represents the -lock flag.
 type LockOption struct {
 	lock bool
@@ -262,6 +318,33 @@ func PluginDir(pluginDir string) *PluginDirOption {
 	return &PluginDirOption{pluginDir}
 }
 
+// PoliciesOption represents evaluating policies against a saved plan.
+type PoliciesOption struct {
+	policies []Policy
+}
+
+// Policies represents evaluating the given policies against the saved plan
+// passed with the DirOrPlan option before applying it. Apply refuses to apply
+// a plan violating any policy with a *PolicyViolationError. The option may be
+// passed multiple times.
+func Policies(policies ...Policy) *PoliciesOption {
+	return &PoliciesOption{policies}
+}
+
+// ProvenanceOption represents recording or verifying the provenance of a
+// saved plan.
+type ProvenanceOption struct {
+	provenance bool
+}
+
+// Provenance represents recording the provenance of the saved plan next to
+// the plan file for Plan, which requires the Out option, and verifying it
+// before applying the saved plan for Apply, which requires the DirOrPlan
+// option. See PlanProvenance.
+func Provenance(provenance bool) *ProvenanceOption {
+	return &ProvenanceOption{provenance}
+}
+
 type ProviderOption struct {
 	provider string
 }
@@ -379,7 +462,7 @@ type TestsDirectoryOption struct {
 	testsDirectory string
 }
 
-// TestsDirectory represents the -tests-directory option (path to tests files)
+// TestsDirectory represents the -test-directory option (path to tests files)
 func TestsDirectory(testsDirectory string) *TestsDirectoryOption {
 	return &TestsDirectoryOption{testsDirectory}
 }
@@ -416,6 +499,44 @@ func Var(assignment string) *VarOption {
 	return &VarOption{assignment}
 }
 
+// VarValueOption represents a typed root module variable value.
+type VarValueOption struct {
+	name      string
+	value     string
+	sensitive bool
+	err       error
+}
+
+// VarValue represents a root module variable value passed with the -var
+// flag. Unlike Var, the value is encoded for OpenTofu: strings, numbers and
+// bools are passed as is, while other Go values, such as slices, maps and
+// structs, and cty.Values are encoded as JSON, which OpenTofu parses as an
+// HCL expression. Template sequences such as ${ in their strings are escaped
+// and passed literally. A nil or null value is an error, as OpenTofu would
+// read it as the string "null" for string variables; omit the option to use
+// the default value of the variable instead.
+//
+// The value is redacted from the logged command line, but visible on the
+// command line of the OpenTofu process and in its output, use SensitiveVar for
+// secrets.
+func VarValue(name string, value any) *VarValueOption {
+	encoded, err := encodeVarValue(value)
+	return &VarValueOption{name: name, value: encoded, err: err}
+}
+
+// SensitiveVar represents a root module variable value encoded like
+// VarValue, which is passed in a TF_VAR_ environment variable of the OpenTofu
+// process rather than on its command line, so that it is neither visible in
+// the process list nor logged.
+//
+// Note that OpenTofu gives environment variables the lowest precedence, so
+// values for the same variable in var files, including terraform.tfvars,
+// take precedence over the value.
+func SensitiveVar(name string, value any) *VarValueOption {
+	encoded, err := encodeVarValue(value)
+	return &VarValueOption{name: name, value: encoded, sensitive: true, err: err}
+}
+
 type VarFileOption struct {
 	path string
 }
@@ -424,6 +545,16 @@ func VarFile(path string) *VarFileOption {
 	return &VarFileOption{path}
 }
 
+// VerboseOption represents the -verbose flag.
+type VerboseOption struct {
+	verbose bool
+}
+
+// Verbose represents the -verbose flag.
+func Verbose(verbose bool) *VerboseOption {
+	return &VerboseOption{verbose}
+}
+
 type VerifyPluginsOption struct {
 	verifyPlugins bool
 }
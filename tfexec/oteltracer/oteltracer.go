@@ -0,0 +1,90 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package oteltracer adapts OpenTelemetry tracers for tfexec, so that every
+// OpenTofu command runs in an OpenTelemetry span:
+//
+//	tf.SetTracer(oteltracer.New(otel.GetTracerProvider()))
+//
+// It is a separate module to keep tfexec free of the OpenTelemetry
+// dependency.
+package oteltracer
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+// instrumentationName identifies the spans created through this package.
+const instrumentationName = "github.com/opentofu/tofu-exec/tfexec"
+
+// New returns a tfexec.Tracer creating spans with a tracer of tp.
+func New(tp trace.TracerProvider) tfexec.Tracer {
+	return &tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...tfexec.TraceAttribute) (context.Context, tfexec.Span) {
+	ctx, s := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes(attrs)...),
+	)
+	return ctx, &span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SpanContext() tfexec.SpanContext {
+	sc := s.span.SpanContext()
+	return tfexec.SpanContext{
+		TraceID:    sc.TraceID(),
+		SpanID:     sc.SpanID(),
+		Sampled:    sc.IsSampled(),
+		TraceState: sc.TraceState().String(),
+	}
+}
+
+func (s *span) SetAttributes(attrs ...tfexec.TraceAttribute) {
+	s.span.SetAttributes(attributes(attrs)...)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func attributes(attrs []tfexec.TraceAttribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,111 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package oteltracer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opentofu/tofu-exec/tfexec"
+)
+
+// fakeTofu prints the traceparent it was started with instead of the
+// current workspace.
+const fakeTofu = `#!/bin/sh
+echo "$TRACEPARENT"
+`
+
+func TestTracer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake OpenTofu binary is a shell script")
+	}
+
+	td := t.TempDir()
+	execPath := filepath.Join(td, "tofu")
+	if err := os.WriteFile(execPath, []byte(fakeTofu), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := tfexec.NewTofu(td, execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tf.SetTracer(New(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "deploy")
+	traceParent, err := tf.WorkspaceShow(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name != "tofu workspace show" {
+		t.Fatalf("unexpected span name %q", span.Name)
+	}
+	if span.SpanKind != trace.SpanKindClient {
+		t.Fatalf("unexpected span kind %s", span.SpanKind)
+	}
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("expected command span to be a child of the span in the context")
+	}
+
+	attrs := attribute.NewSet(span.Attributes...)
+	for key, expected := range map[attribute.Key]attribute.Value{
+		"tofu.subcommand":  attribute.StringValue("workspace show"),
+		"tofu.working_dir": attribute.StringValue(td),
+		"tofu.workspace":   attribute.StringValue("default"),
+		"tofu.exit_code":   attribute.IntValue(0),
+	} {
+		if actual, ok := attrs.Value(key); !ok || actual != expected {
+			t.Fatalf("expected attribute %s to be %s, got %s", key, expected.Emit(), actual.Emit())
+		}
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    span.SpanContext.TraceID(),
+		SpanID:     span.SpanContext.SpanID(),
+		TraceFlags: span.SpanContext.TraceFlags(),
+	})
+	expected := tfexec.SpanContext{TraceID: sc.TraceID(), SpanID: sc.SpanID(), Sampled: sc.IsSampled()}
+	if traceParent != expected.TraceParent() {
+		t.Fatalf("expected TRACEPARENT %s, got %s", expected.TraceParent(), traceParent)
+	}
+}
+
+func TestTracer_recordError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := New(tp).Start(context.Background(), "tofu plan")
+	span.RecordError(context.Canceled)
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || len(spans[0].Events) != 1 {
+		t.Fatalf("expected error status and event, got %+v", spans[0].Status)
+	}
+}
@@ -14,20 +14,24 @@ import (
 )
 
 type planConfig struct {
-	destroy      bool
-	dir          string
-	lock         bool
-	lockTimeout  string
-	out          string
-	parallelism  int
-	reattachInfo ReattachInfo
-	refresh      bool
-	refreshOnly  bool
-	replaceAddrs []string
-	state        string
-	targets      []string
-	vars         []string
-	varFiles     []string
+	destroy           bool
+	dir               string
+	excludes          []string
+	generateConfigOut string
+	lock              bool
+	lockTimeout       string
+	out               string
+	parallelism       int
+	provenance        bool
+	reattachInfo      ReattachInfo
+	refresh           bool
+	refreshOnly       bool
+	replaceAddrs      []string
+	state             string
+	targets           []string
+	typedVars         []*VarValueOption
+	vars              []string
+	varFiles          []string
 }
 
 var defaultPlanOptions = planConfig{
@@ -51,6 +55,10 @@ func (opt *VarFileOption) configurePlan(conf *planConfig) {
 	conf.varFiles = append(conf.varFiles, opt.path)
 }
 
+func (opt *VarValueOption) configurePlan(conf *planConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
 func (opt *VarOption) configurePlan(conf *planConfig) {
 	conf.vars = append(conf.vars, opt.assignment)
 }
@@ -59,6 +67,10 @@ func (opt *TargetOption) configurePlan(conf *planConfig) {
 	conf.targets = append(conf.targets, opt.target)
 }
 
+func (opt *ExcludeOption) configurePlan(conf *planConfig) {
+	conf.excludes = append(conf.excludes, opt.exclude)
+}
+
 func (opt *StateOption) configurePlan(conf *planConfig) {
 	conf.state = opt.path
 }
@@ -83,6 +95,10 @@ func (opt *ParallelismOption) configurePlan(conf *planConfig) {
 	conf.parallelism = opt.parallelism
 }
 
+func (opt *GenerateConfigOutOption) configurePlan(conf *planConfig) {
+	conf.generateConfigOut = opt.path
+}
+
 func (opt *OutOption) configurePlan(conf *planConfig) {
 	conf.out = opt.path
 }
@@ -99,6 +115,10 @@ func (opt *DestroyFlagOption) configurePlan(conf *planConfig) {
 	conf.destroy = opt.destroy
 }
 
+func (opt *ProvenanceOption) configurePlan(conf *planConfig) {
+	conf.provenance = opt.provenance
+}
+
 // Plan executes `tofu plan` with the specified options and waits for it
 // to complete.
 //
@@ -112,11 +132,7 @@ func (tf *Tofu) Plan(ctx context.Context, opts ...PlanOption) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	err = tf.runTofuCmd(ctx, cmd)
-	if err != nil && cmd.ProcessState.ExitCode() == 2 {
-		return true, nil
-	}
-	return false, err
+	return tf.runPlanCmd(ctx, cmd, opts)
 }
 
 // PlanJSON executes `tofu plan` with the specified options as well as the
@@ -134,19 +150,65 @@ func (tf *Tofu) Plan(ctx context.Context, opts ...PlanOption) (bool, error) {
 // PlanJSON is likely to be removed in a future major version in favour of
 // Plan returning JSON by default.
 func (tf *Tofu) PlanJSON(ctx context.Context, w io.Writer, opts ...PlanOption) (bool, error) {
-	tf.SetStdout(w)
+	cmd, err := tf.planJSONCmd(ctx, opts...)
+	if err != nil {
+		return false, err
+	}
+	cmd.Stdout = mergeWriters(cmd.Stdout, w)
+
+	return tf.runPlanCmd(ctx, cmd, opts)
+}
 
+// PlanEvents executes `tofu plan` with the specified options as well as the
+// `-json` flag and waits for it to complete.
+//
+// The handler is called with every machine-readable UI message as it is
+// emitted, see Event for the available message types.
+//
+// The returned boolean is false when the plan diff is empty (no changes) and
+// true when the plan diff is non-empty (changes present).
+//
+// The returned error is nil if `tofu plan` has been executed and exits
+// with either 0 or 2.
+func (tf *Tofu) PlanEvents(ctx context.Context, handler EventHandler, opts ...PlanOption) (bool, error) {
 	cmd, err := tf.planJSONCmd(ctx, opts...)
 	if err != nil {
 		return false, err
 	}
 
-	err = tf.runTofuCmd(ctx, cmd)
+	cmd.Stdout = mergeWriters(cmd.Stdout, newEventWriter(handler))
+
+	return tf.runPlanCmd(ctx, cmd, opts)
+}
+
+// runPlanCmd runs a plan command, for which -detailed-exitcode makes exit
+// status 2 indicate success with changes present, and records the provenance
+// of the saved plan if requested.
+func (tf *Tofu) runPlanCmd(ctx context.Context, cmd *exec.Cmd, opts []PlanOption) (bool, error) {
+	hasChanges := false
+	err := tf.runTofuCmd(ctx, cmd)
 	if err != nil && cmd.ProcessState.ExitCode() == 2 {
-		return true, nil
+		hasChanges = true
+		err = nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	c := defaultPlanOptions
+
+	for _, o := range opts {
+		o.configurePlan(&c)
+	}
+
+	if c.provenance {
+		err = tf.writePlanProvenance(ctx, c.out, c.state)
+		if err != nil {
+			return hasChanges, err
+		}
 	}
 
-	return false, err
+	return hasChanges, nil
 }
 
 func (tf *Tofu) planCmd(ctx context.Context, opts ...PlanOption) (*exec.Cmd, error) {
@@ -185,6 +247,9 @@ func (tf *Tofu) buildPlanArgs(c planConfig) ([]string, error) {
 	args := []string{"plan", "-no-color", "-input=false", "-detailed-exitcode"}
 
 	// string opts: only pass if set
+	if c.generateConfigOut != "" {
+		args = append(args, "-generate-config-out="+c.generateConfigOut)
+	}
 	if c.lockTimeout != "" {
 		args = append(args, "-lock-timeout="+c.lockTimeout)
 	}
@@ -210,6 +275,10 @@ func (tf *Tofu) buildPlanArgs(c planConfig) ([]string, error) {
 		args = append(args, "-refresh-only")
 	}
 
+	if c.provenance && c.out == "" {
+		return nil, fmt.Errorf("the Provenance option requires the Out option")
+	}
+
 	// unary flags: pass if true
 	if c.replaceAddrs != nil {
 		for _, addr := range c.replaceAddrs {
@@ -226,22 +295,37 @@ func (tf *Tofu) buildPlanArgs(c planConfig) ([]string, error) {
 			args = append(args, "-target="+ta)
 		}
 	}
+	if c.excludes != nil {
+		for _, ex := range c.excludes {
+			args = append(args, "-exclude="+ex)
+		}
+	}
 	if c.vars != nil {
 		for _, v := range c.vars {
 			args = append(args, "-var", v)
 		}
 	}
+	args = append(args, typedVarArgs(c.typedVars)...)
 
 	return args, nil
 }
 
 func (tf *Tofu) buildPlanCmd(ctx context.Context, c planConfig, args []string) (*exec.Cmd, error) {
+	err := tf.checkExcludes(ctx, c.targets, c.excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	// optional positional argument
 	if c.dir != "" {
 		args = append(args, c.dir)
 	}
 
 	mergeEnv := map[string]string{}
+	err = addTypedVarEnv(mergeEnv, c.typedVars)
+	if err != nil {
+		return nil, err
+	}
 	if c.reattachInfo != nil {
 		reattachStr, err := c.reattachInfo.marshalString()
 		if err != nil {
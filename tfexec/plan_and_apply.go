@@ -0,0 +1,150 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ApprovalDecision is the decision of an Approver.
+type ApprovalDecision struct {
+	Approved bool
+
+	// Reason explains the decision, for example who approved the plan or
+	// which policy rejected it. It is returned in the PlanRejectedError of a
+	// rejected plan.
+	Reason string
+}
+
+// Approve returns a decision approving a plan.
+func Approve(reason string) ApprovalDecision {
+	return ApprovalDecision{Approved: true, Reason: reason}
+}
+
+// Reject returns a decision rejecting a plan.
+func Reject(reason string) ApprovalDecision {
+	return ApprovalDecision{Approved: false, Reason: reason}
+}
+
+// Approver decides whether a plan may be applied, for example by prompting
+// a user, requesting an approval through a chat system or evaluating a
+// policy.
+//
+// Approve is called with the context passed to PlanAndApply and should
+// return once it is done. Returning an error aborts PlanAndApply without
+// applying the plan.
+type Approver interface {
+	Approve(ctx context.Context, plan *PlanResult) (ApprovalDecision, error)
+}
+
+// ApproverFunc adapts a function to the Approver interface.
+type ApproverFunc func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error)
+
+func (f ApproverFunc) Approve(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+	return f(ctx, plan)
+}
+
+// ApprovalTimeout returns an Approver which rejects the plan if approver
+// does not decide within the given duration. The context passed to
+// approver is cancelled once the timeout expires.
+func ApprovalTimeout(approver Approver, timeout time.Duration) Approver {
+	return ApproverFunc(func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+		approvalCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		decision, err := approver.Approve(approvalCtx, plan)
+		if ctx.Err() == nil && errors.Is(approvalCtx.Err(), context.DeadlineExceeded) {
+			return Reject(fmt.Sprintf("no decision within %s", timeout)), nil
+		}
+		return decision, err
+	})
+}
+
+// PlanAndApply plans with the given options, passes the plan to the
+// approver, and applies exactly the approved plan.
+//
+// The plan is saved to a temporary file, which is removed before returning as
+// plans can contain sensitive values. The Out option is therefore not
+// supported. The Lock, LockTimeout, Parallelism, Provenance, Reattach and
+// State options are used for the apply too.
+//
+// If the plan contains no changes, the approver is not called and nothing is
+// applied. The returned boolean is true if the plan was applied. If the
+// approver rejected the plan, a *PlanRejectedError is returned.
+func (tf *Tofu) PlanAndApply(ctx context.Context, approver Approver, opts ...PlanOption) (bool, error) {
+	c := defaultPlanOptions
+
+	for _, o := range opts {
+		o.configurePlan(&c)
+	}
+
+	if c.out != "" {
+		return false, fmt.Errorf("the Out option is not supported for PlanAndApply")
+	}
+
+	result, err := tf.PlanDetailed(ctx, opts...)
+	if err != nil {
+		return false, err
+	}
+	defer result.Close()
+
+	if !result.HasChanges {
+		return false, nil
+	}
+
+	checksum, err := fileChecksum(result.PlanFile)
+	if err != nil {
+		return false, err
+	}
+
+	decision, err := approver.Approve(ctx, result)
+	if err != nil {
+		return false, fmt.Errorf("unable to get approval for plan: %w", err)
+	}
+	if !decision.Approved {
+		return false, &PlanRejectedError{Reason: decision.Reason}
+	}
+
+	// make sure the plan file was not changed while waiting for approval
+	current, err := fileChecksum(result.PlanFile)
+	if err != nil {
+		return false, err
+	}
+	if current != checksum {
+		return false, &StalePlanError{
+			PlanFile: result.PlanFile,
+			Mismatches: []ProvenanceMismatch{
+				{Field: "plan_checksum", Recorded: checksum, Current: current},
+			},
+		}
+	}
+
+	applyOpts := []ApplyOption{
+		DirOrPlan(result.PlanFile),
+		Lock(c.lock),
+		Parallelism(c.parallelism),
+		Provenance(c.provenance),
+	}
+	if c.lockTimeout != "" {
+		applyOpts = append(applyOpts, LockTimeout(c.lockTimeout))
+	}
+	if c.reattachInfo != nil {
+		applyOpts = append(applyOpts, Reattach(c.reattachInfo))
+	}
+	if c.state != "" {
+		applyOpts = append(applyOpts, State(c.state))
+	}
+
+	err = tf.Apply(ctx, applyOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
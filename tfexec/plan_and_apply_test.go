@@ -0,0 +1,80 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+)
+
+func TestApprovalTimeout(t *testing.T) {
+	t.Run("decision in time", func(t *testing.T) {
+		approver := ApprovalTimeout(ApproverFunc(func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+			return Approve("approved by test"), nil
+		}), time.Minute)
+
+		decision, err := approver.Approve(context.Background(), &PlanResult{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decision.Approved || decision.Reason != "approved by test" {
+			t.Fatalf("unexpected decision %#v", decision)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		approver := ApprovalTimeout(ApproverFunc(func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+			<-ctx.Done()
+			return ApprovalDecision{}, ctx.Err()
+		}), 10*time.Millisecond)
+
+		decision, err := approver.Approve(context.Background(), &PlanResult{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decision.Approved || decision.Reason != "no decision within 10ms" {
+			t.Fatalf("unexpected decision %#v", decision)
+		}
+	})
+
+	t.Run("parent cancelled", func(t *testing.T) {
+		approver := ApprovalTimeout(ApproverFunc(func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+			<-ctx.Done()
+			return ApprovalDecision{}, ctx.Err()
+		}), time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := approver.Approve(ctx, &PlanResult{})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestPlanAndApply_out(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approver := ApproverFunc(func(ctx context.Context, plan *PlanResult) (ApprovalDecision, error) {
+		t.Fatal("approver should not be called")
+		return ApprovalDecision{}, nil
+	})
+
+	_, err = tf.PlanAndApply(context.Background(), approver, Out("plan.tfplan"))
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// PlanResult is the result of PlanDetailed, combining the saved plan file
+// with its parsed contents.
+//
+// A PlanResult can be passed to Apply as an ApplyOption to apply the saved
+// plan, which is equivalent to passing DirOrPlan(result.PlanFile).
+type PlanResult struct {
+	// PlanFile is the path of the saved plan file.
+	PlanFile string
+
+	// Plan is the parsed plan, as returned by ShowPlanFile.
+	Plan *tfjson.Plan
+
+	// HasChanges is true when tofu plan exited with status 2, i.e. the plan
+	// contains changes to resources or outputs.
+	HasChanges bool
+
+	// Add, Change and Destroy are the number of resource changes as counted
+	// in the plan summary of the CLI, i.e. a replaced resource counts
+	// towards both Add and Destroy.
+	Add     int
+	Change  int
+	Destroy int
+
+	// Replace is the number of resources to be replaced.
+	Replace int
+
+	// Import is the number of resources to be imported.
+	Import int
+
+	// Drift lists the changes made outside of OpenTofu which were detected
+	// while refreshing.
+	Drift []*tfjson.ResourceChange
+
+	// Outputs holds the planned changes to the root module outputs.
+	Outputs map[string]*tfjson.Change
+
+	// Checks holds the results of the check blocks, variable validations
+	// and pre- and postconditions evaluated during the plan.
+	Checks []tfjson.CheckResultStatic
+
+	// removePlanFile is set if PlanFile was created by PlanDetailed.
+	removePlanFile bool
+}
+
+func (r *PlanResult) configureApply(conf *applyConfig) {
+	conf.dirOrPlan = r.PlanFile
+}
+
+// Close removes the plan file, along with its provenance record, if it was
+// created by PlanDetailed, i.e. if no Out option was given. It is a no-op
+// otherwise.
+func (r *PlanResult) Close() error {
+	if !r.removePlanFile {
+		return nil
+	}
+	for _, path := range []string{PlanProvenancePath(r.PlanFile), r.PlanFile} {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlanDetailed represents the tofu plan subcommand, followed by tofu show of
+// the saved plan.
+//
+// Unless the Out option is given, the plan is saved to a temporary file which
+// must be removed with PlanResult.Close once the plan is no longer needed.
+func (tf *Tofu) PlanDetailed(ctx context.Context, opts ...PlanOption) (*PlanResult, error) {
+	c := defaultPlanOptions
+
+	for _, o := range opts {
+		o.configurePlan(&c)
+	}
+
+	result := &PlanResult{PlanFile: c.out}
+	if result.PlanFile == "" {
+		f, err := os.CreateTemp("", "tfexec-*.tfplan")
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+
+		result.PlanFile = f.Name()
+		result.removePlanFile = true
+		opts = append(opts, Out(result.PlanFile))
+	}
+
+	hasChanges, err := tf.Plan(ctx, opts...)
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	var showOpts []ShowOption
+	if c.reattachInfo != nil {
+		showOpts = append(showOpts, Reattach(c.reattachInfo))
+	}
+
+	plan, err := tf.ShowPlanFile(withoutResult(ctx), result.PlanFile, showOpts...)
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	result.HasChanges = hasChanges
+	result.summarize(plan)
+
+	return result, nil
+}
+
+// summarize sets the plan and the fields derived from it.
+func (r *PlanResult) summarize(plan *tfjson.Plan) {
+	r.Plan = plan
+	r.Drift = plan.ResourceDrift
+	r.Outputs = plan.OutputChanges
+	r.Checks = plan.Checks
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		actions := rc.Change.Actions
+		switch {
+		case actions.Replace():
+			r.Add++
+			r.Destroy++
+			r.Replace++
+		case actions.Create():
+			r.Add++
+		case actions.Update():
+			r.Change++
+		case actions.Delete():
+			r.Destroy++
+		}
+		if rc.Change.Importing != nil {
+			r.Import++
+		}
+	}
+}
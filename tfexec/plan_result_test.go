@@ -0,0 +1,103 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestPlanResult_summarize(t *testing.T) {
+	change := func(importing bool, actions ...tfjson.Action) *tfjson.ResourceChange {
+		rc := &tfjson.ResourceChange{
+			Change: &tfjson.Change{Actions: actions},
+		}
+		if importing {
+			rc.Change.Importing = &tfjson.Importing{ID: "123"}
+		}
+		return rc
+	}
+
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			change(false, tfjson.ActionCreate),
+			change(false, tfjson.ActionCreate),
+			change(false, tfjson.ActionUpdate),
+			change(false, tfjson.ActionDelete),
+			change(false, tfjson.ActionDelete, tfjson.ActionCreate),
+			change(false, tfjson.ActionCreate, tfjson.ActionDelete),
+			change(true, tfjson.ActionNoop),
+			change(false, tfjson.ActionRead),
+		},
+		ResourceDrift: []*tfjson.ResourceChange{
+			change(false, tfjson.ActionUpdate),
+		},
+		OutputChanges: map[string]*tfjson.Change{
+			"foo": {Actions: tfjson.Actions{tfjson.ActionCreate}},
+		},
+	}
+
+	var r PlanResult
+	r.summarize(plan)
+
+	for _, c := range []struct {
+		name     string
+		expected int
+		actual   int
+	}{
+		{"add", 4, r.Add},
+		{"change", 1, r.Change},
+		{"destroy", 3, r.Destroy},
+		{"replace", 2, r.Replace},
+		{"import", 1, r.Import},
+		{"drift", 1, len(r.Drift)},
+		{"outputs", 1, len(r.Outputs)},
+	} {
+		if c.actual != c.expected {
+			t.Errorf("expected %d %s, got %d", c.expected, c.name, c.actual)
+		}
+	}
+}
+
+func TestPlanResult_applyOption(t *testing.T) {
+	r := &PlanResult{PlanFile: "planfile"}
+
+	var c applyConfig
+	r.configureApply(&c)
+
+	if c.dirOrPlan != "planfile" {
+		t.Fatalf("expected dirOrPlan %q, got %q", "planfile", c.dirOrPlan)
+	}
+}
+
+func TestPlanResult_Close(t *testing.T) {
+	td := t.TempDir()
+
+	managed := filepath.Join(td, "managed.tfplan")
+	userProvided := filepath.Join(td, "user.tfplan")
+	for _, path := range []string{managed, userProvided} {
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := (&PlanResult{PlanFile: managed, removePlanFile: true}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(managed); !os.IsNotExist(err) {
+		t.Fatalf("expected managed plan file to be removed, got %v", err)
+	}
+
+	if err := (&PlanResult{PlanFile: userProvided}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(userProvided); err != nil {
+		t.Fatalf("expected user provided plan file to be kept, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ package tfexec
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
@@ -103,6 +104,91 @@ func TestPlanCmd(t *testing.T) {
 			"-refresh-only",
 		}, nil, planCmd)
 	})
+	t.Run("generate config out", func(t *testing.T) {
+		planCmd, err := tf.planCmd(context.Background(), GenerateConfigOut("generated.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"plan",
+			"-no-color",
+			"-input=false",
+			"-detailed-exitcode",
+			"-generate-config-out=generated.tf",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"-parallelism=10",
+			"-refresh=true",
+		}, nil, planCmd)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		planCmd, err := tf.planCmd(context.Background(), Exclude("null_resource.foo"), Exclude("module.bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"plan",
+			"-no-color",
+			"-input=false",
+			"-detailed-exitcode",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"-parallelism=10",
+			"-refresh=true",
+			"-exclude=null_resource.foo",
+			"-exclude=module.bar",
+		}, nil, planCmd)
+	})
+
+	t.Run("exclude with target", func(t *testing.T) {
+		_, err := tf.planCmd(context.Background(), Target("null_resource.foo"), Exclude("module.bar"))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+
+	t.Run("provenance without out", func(t *testing.T) {
+		_, err := tf.planCmd(context.Background(), Provenance(true))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+
+	t.Run("typed vars", func(t *testing.T) {
+		planCmd, err := tf.planCmd(context.Background(),
+			Var("android=paranoid"),
+			VarValue("tags", map[string]string{"crew": "heart of gold"}),
+			SensitiveVar("password", "don't panic"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"plan",
+			"-no-color",
+			"-input=false",
+			"-detailed-exitcode",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"-parallelism=10",
+			"-refresh=true",
+			"-var", "android=paranoid",
+			"-var", `tags={"crew":"heart of gold"}`,
+		}, map[string]string{
+			"TF_VAR_password": "don't panic",
+		}, planCmd)
+	})
+
+	t.Run("typed var encoding error", func(t *testing.T) {
+		_, err := tf.planCmd(context.Background(), SensitiveVar("callback", func() {}))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
 }
 
 func TestPlanJSONCmd(t *testing.T) {
@@ -180,3 +266,25 @@ func TestPlanJSONCmd(t *testing.T) {
 		}, nil, planCmd)
 	})
 }
+
+func TestPlanCmd_excludeVersionMismatch(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1_8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// empty env, to avoid environ mismatch in testing
+	tf.SetEnv(map[string]string{})
+
+	_, err = tf.planCmd(context.Background(), Exclude("null_resource.foo"))
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	var mismatchErr *ErrVersionMismatch
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("expected ErrVersionMismatch, got %T %s", err, err)
+	}
+}
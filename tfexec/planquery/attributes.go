@@ -0,0 +1,210 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planquery
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// AttributeChange describes a changed attribute of a resource change.
+//
+// Sensitive values are never exposed: Before or After is nil whenever
+// BeforeSensitive or AfterSensitive is set, even though the plan contains the
+// value.
+type AttributeChange struct {
+	// Path is the path of the attribute, for example tags["kubernetes.io/role"],
+	// ebs_block_device[0].volume_size or instance_type.
+	Path string
+
+	Before interface{}
+	After  interface{}
+
+	BeforeSensitive bool
+	AfterSensitive  bool
+
+	// AfterUnknown is set when the new value is only known after apply, in
+	// which case After is nil.
+	AfterUnknown bool
+}
+
+// ChangedAttributes returns the attributes changed by a resource change,
+// sorted by path. Nested objects, maps and lists of equal length are compared
+// element by element, while any other change to a value, such as a change to
+// the length of a list or a set, is reported for the value as a whole.
+//
+// A change to the sensitivity of a value is reported even if the value itself
+// is unchanged.
+func ChangedAttributes(rc *tfjson.ResourceChange) []AttributeChange {
+	if rc.Change == nil {
+		return nil
+	}
+
+	c := rc.Change
+	var changes []AttributeChange
+	walkAttributes(&changes, "", attrValue{
+		before:          c.Before,
+		after:           c.After,
+		beforeSensitive: c.BeforeSensitive,
+		afterSensitive:  c.AfterSensitive,
+		afterUnknown:    c.AfterUnknown,
+	})
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// attrValue holds a value of a change along with its sensitivity and
+// unknown markers, which are either a bool or a structure of the same shape
+// as the value.
+type attrValue struct {
+	before          interface{}
+	after           interface{}
+	beforeSensitive interface{}
+	afterSensitive  interface{}
+	afterUnknown    interface{}
+}
+
+func (v attrValue) attr(key string) attrValue {
+	return attrValue{
+		before:          mapElem(v.before, key),
+		after:           mapElem(v.after, key),
+		beforeSensitive: mapElem(v.beforeSensitive, key),
+		afterSensitive:  mapElem(v.afterSensitive, key),
+		afterUnknown:    mapElem(v.afterUnknown, key),
+	}
+}
+
+func (v attrValue) index(i int) attrValue {
+	return attrValue{
+		before:          listElem(v.before, i),
+		after:           listElem(v.after, i),
+		beforeSensitive: listElem(v.beforeSensitive, i),
+		afterSensitive:  listElem(v.afterSensitive, i),
+		afterUnknown:    listElem(v.afterUnknown, i),
+	}
+}
+
+func walkAttributes(changes *[]AttributeChange, path string, v attrValue) {
+	beforeSensitive := v.beforeSensitive == true
+	afterSensitive := v.afterSensitive == true
+	afterUnknown := v.afterUnknown == true
+
+	if !beforeSensitive && !afterSensitive && !afterUnknown {
+		// descend into objects and maps
+		beforeMap, beforeIsMap := v.before.(map[string]interface{})
+		afterMap, afterIsMap := v.after.(map[string]interface{})
+		if (beforeIsMap || v.before == nil) && (afterIsMap || v.after == nil) && (beforeIsMap || afterIsMap) {
+			keys := map[string]struct{}{}
+			for k := range beforeMap {
+				keys[k] = struct{}{}
+			}
+			for k := range afterMap {
+				keys[k] = struct{}{}
+			}
+			// attributes which are only known after apply are absent from
+			// the after value
+			if unknown, ok := v.afterUnknown.(map[string]interface{}); ok {
+				for k := range unknown {
+					keys[k] = struct{}{}
+				}
+			}
+			for k := range keys {
+				walkAttributes(changes, joinAttrPath(path, k), v.attr(k))
+			}
+			return
+		}
+
+		// descend into lists of the same length, or lists being created or
+		// destroyed
+		beforeList, beforeIsList := v.before.([]interface{})
+		afterList, afterIsList := v.after.([]interface{})
+		if (beforeIsList || v.before == nil) && (afterIsList || v.after == nil) && (beforeIsList || afterIsList) &&
+			(!beforeIsList || !afterIsList || len(beforeList) == len(afterList)) {
+			n := len(beforeList)
+			if len(afterList) > n {
+				n = len(afterList)
+			}
+			for i := 0; i < n; i++ {
+				walkAttributes(changes, path+"["+strconv.Itoa(i)+"]", v.index(i))
+			}
+			return
+		}
+	}
+
+	if !afterUnknown && beforeSensitive == afterSensitive && reflect.DeepEqual(v.before, v.after) && !containsTrue(v.afterUnknown) {
+		return
+	}
+
+	change := AttributeChange{
+		Path:            path,
+		BeforeSensitive: beforeSensitive || containsTrue(v.beforeSensitive),
+		AfterSensitive:  afterSensitive || containsTrue(v.afterSensitive),
+		AfterUnknown:    afterUnknown || containsTrue(v.afterUnknown),
+	}
+	if !change.BeforeSensitive {
+		change.Before = v.before
+	}
+	if !change.AfterSensitive && !change.AfterUnknown {
+		change.After = v.after
+	}
+	*changes = append(*changes, change)
+}
+
+func mapElem(v interface{}, key string) interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m[key]
+	}
+	return nil
+}
+
+func listElem(v interface{}, i int) interface{} {
+	if l, ok := v.([]interface{}); ok && i < len(l) {
+		return l[i]
+	}
+	return nil
+}
+
+// containsTrue returns whether a sensitivity or unknown marker marks any
+// part of the value.
+func containsTrue(marker interface{}) bool {
+	switch m := marker.(type) {
+	case bool:
+		return m
+	case map[string]interface{}:
+		for _, v := range m {
+			if containsTrue(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range m {
+			if containsTrue(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var attrNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+func joinAttrPath(path string, key string) string {
+	if !attrNameRegexp.MatchString(key) {
+		return path + "[" + fmt.Sprintf("%q", key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,282 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package planquery selects and inspects the resource changes of a plan, as
+// returned by (*tfexec.Tofu).ShowPlanFile.
+package planquery
+
+import (
+	"regexp"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Action classifies the change to a resource instance. Unlike tfjson.Actions,
+// a replacement is a single Action distinguishing the order of the delete and
+// create operations.
+type Action string
+
+const (
+	ActionNoOp   Action = "no-op"
+	ActionCreate Action = "create"
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+
+	// ActionDeleteThenCreate is a replacement which deletes the existing
+	// object before creating the new one, the default.
+	ActionDeleteThenCreate Action = "delete-then-create"
+
+	// ActionCreateThenDelete is a replacement which creates the new object
+	// before deleting the existing one, due to create_before_destroy.
+	ActionCreateThenDelete Action = "create-then-delete"
+
+	// ActionReplace is only used for selecting changes, matching both
+	// ActionDeleteThenCreate and ActionCreateThenDelete. ActionOf never
+	// returns it.
+	ActionReplace Action = "replace"
+
+	// ActionUnknown is returned by ActionOf for a change whose actions are
+	// not recognised.
+	ActionUnknown Action = "unknown"
+)
+
+// ActionOf returns the Action of a resource change.
+func ActionOf(rc *tfjson.ResourceChange) Action {
+	if rc.Change == nil {
+		return ActionUnknown
+	}
+
+	actions := rc.Change.Actions
+	switch {
+	case actions.NoOp():
+		return ActionNoOp
+	case actions.Create():
+		return ActionCreate
+	case actions.Read():
+		return ActionRead
+	case actions.Update():
+		return ActionUpdate
+	case actions.Delete():
+		return ActionDelete
+	case actions.DestroyBeforeCreate():
+		return ActionDeleteThenCreate
+	case actions.CreateBeforeDestroy():
+		return ActionCreateThenDelete
+	}
+	return ActionUnknown
+}
+
+// IsReplace returns whether the action is a replacement.
+func (a Action) IsReplace() bool {
+	return a == ActionDeleteThenCreate || a == ActionCreateThenDelete || a == ActionReplace
+}
+
+func (a Action) matches(actual Action) bool {
+	if a == ActionReplace {
+		return actual.IsReplace()
+	}
+	return a == actual
+}
+
+// Query is a selection of resource changes. Each of its filter methods
+// returns a new Query, leaving the receiver unchanged, so that queries can be
+// chained and reused:
+//
+//	deletes := planquery.New(plan).Actions(planquery.ActionDelete, planquery.ActionReplace)
+//	for _, rc := range deletes.Module("module.db", true).ResourceChanges() {
+//		...
+//	}
+type Query struct {
+	changes []*tfjson.ResourceChange
+}
+
+// New returns a Query selecting all resource changes of the plan.
+func New(plan *tfjson.Plan) *Query {
+	if plan == nil {
+		return &Query{}
+	}
+	return &Query{changes: plan.ResourceChanges}
+}
+
+// ResourceChanges returns the selected resource changes, in plan order.
+func (q *Query) ResourceChanges() []*tfjson.ResourceChange {
+	return q.changes
+}
+
+// Len returns the number of selected resource changes.
+func (q *Query) Len() int {
+	return len(q.changes)
+}
+
+// Addresses returns the addresses of the selected resource changes, in plan
+// order.
+func (q *Query) Addresses() []string {
+	addrs := make([]string, len(q.changes))
+	for i, rc := range q.changes {
+		addrs[i] = rc.Address
+	}
+	return addrs
+}
+
+// Filter selects the resource changes for which fn returns true.
+func (q *Query) Filter(fn func(*tfjson.ResourceChange) bool) *Query {
+	var changes []*tfjson.ResourceChange
+	for _, rc := range q.changes {
+		if fn(rc) {
+			changes = append(changes, rc)
+		}
+	}
+	return &Query{changes: changes}
+}
+
+// Actions selects the resource changes with any of the given actions.
+func (q *Query) Actions(actions ...Action) *Query {
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		actual := ActionOf(rc)
+		for _, a := range actions {
+			if a.matches(actual) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Changed selects the resource changes which are not no-ops or reads, i.e.
+// which create, update, delete or replace an object.
+func (q *Query) Changed() *Query {
+	return q.Actions(ActionCreate, ActionUpdate, ActionDelete, ActionReplace)
+}
+
+// Address selects the resource changes with an address matching any of the
+// given patterns. In patterns, "*" matches any sequence of characters and
+// "?" matches any single character; all other characters, including "[" and
+// ".", match themselves. For example, module.app.aws_instance.* matches all
+// aws_instance resources of the module.app module and aws_instance.web[*]
+// matches all instances of aws_instance.web.
+func (q *Query) Address(patterns ...string) *Query {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = globRegexp(p)
+	}
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		for _, re := range res {
+			if re.MatchString(rc.Address) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Module selects the resource changes in the given module, for example
+// module.app or module.app["blue"].module.db. A module given without an
+// instance key selects every instance of a module using count or for_each,
+// such as module.app[0] or module.app["blue"]. The empty string selects the
+// root module. If recursive is true, changes in the descendants of the
+// module are selected too.
+func (q *Query) Module(module string, recursive bool) *Query {
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		return inModule(rc.ModuleAddress, module, recursive)
+	})
+}
+
+// inModule reports whether the module instance address is the given module,
+// or one of its instances or, if recursive is true, descendants.
+func inModule(address string, module string, recursive bool) bool {
+	if module == "" {
+		return address == "" || recursive
+	}
+	if !strings.HasPrefix(address, module) {
+		return false
+	}
+
+	rest := address[len(module):]
+	if strings.HasPrefix(rest, "[") && !strings.HasSuffix(module, "]") {
+		n := instanceKeyLen(rest)
+		if n < 0 {
+			return false
+		}
+		rest = rest[n:]
+	}
+
+	if rest == "" {
+		return true
+	}
+	return recursive && strings.HasPrefix(rest, ".")
+}
+
+// instanceKeyLen returns the length of the instance key at the start of s,
+// such as [0] or ["blue"], or -1 if s does not start with a valid key.
+func instanceKeyLen(s string) int {
+	if strings.HasPrefix(s, `["`) {
+		// string keys may contain brackets and escaped quotes
+		for i := 2; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				if i+1 < len(s) && s[i+1] == ']' {
+					return i + 2
+				}
+				return -1
+			}
+		}
+		return -1
+	}
+	if i := strings.IndexByte(s, ']'); i >= 0 {
+		return i + 1
+	}
+	return -1
+}
+
+// Provider selects the resource changes handled by the given provider. The
+// provider may be given as its fully qualified source address, for example
+// registry.opentofu.org/hashicorp/aws, or without the hostname, for example
+// hashicorp/aws.
+func (q *Query) Provider(provider string) *Query {
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		return rc.ProviderName == provider || strings.HasSuffix(rc.ProviderName, "/"+provider)
+	})
+}
+
+// Type selects the resource changes to resources of any of the given types,
+// for example aws_instance.
+func (q *Query) Type(types ...string) *Query {
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		for _, t := range types {
+			if rc.Type == t {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Mode selects the resource changes to managed resources or data sources.
+func (q *Query) Mode(mode tfjson.ResourceMode) *Query {
+	return q.Filter(func(rc *tfjson.ResourceChange) bool {
+		return rc.Mode == mode
+	})
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
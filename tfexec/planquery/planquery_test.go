@@ -0,0 +1,395 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func testPlan(t *testing.T) *tfjson.Plan {
+	t.Helper()
+
+	change := func(addr, module, typ, provider string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address:       addr,
+			ModuleAddress: module,
+			Mode:          tfjson.ManagedResourceMode,
+			Type:          typ,
+			ProviderName:  provider,
+			Change:        &tfjson.Change{Actions: actions},
+		}
+	}
+
+	const (
+		aws    = "registry.opentofu.org/hashicorp/aws"
+		random = "registry.opentofu.org/hashicorp/random"
+	)
+
+	data := change("data.aws_ami.ubuntu", "", "aws_ami", aws, tfjson.ActionRead)
+	data.Mode = tfjson.DataResourceMode
+
+	return &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			change("aws_instance.web[0]", "", "aws_instance", aws, tfjson.ActionCreate),
+			change("aws_instance.web[1]", "", "aws_instance", aws, tfjson.ActionNoop),
+			change("random_pet.name", "", "random_pet", random, tfjson.ActionUpdate),
+			change("module.db.aws_db_instance.main", "module.db", "aws_db_instance", aws, tfjson.ActionDelete, tfjson.ActionCreate),
+			change("module.db.module.backup.aws_s3_bucket.this", "module.db.module.backup", "aws_s3_bucket", aws, tfjson.ActionCreate, tfjson.ActionDelete),
+			change("module.app.aws_instance.web", "module.app", "aws_instance", aws, tfjson.ActionDelete),
+			data,
+		},
+	}
+}
+
+func TestActionOf(t *testing.T) {
+	expected := []Action{
+		ActionCreate,
+		ActionNoOp,
+		ActionUpdate,
+		ActionDeleteThenCreate,
+		ActionCreateThenDelete,
+		ActionDelete,
+		ActionRead,
+	}
+
+	var actual []Action
+	for _, rc := range testPlan(t).ResourceChanges {
+		actual = append(actual, ActionOf(rc))
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	plan := testPlan(t)
+
+	for _, c := range []struct {
+		name     string
+		query    *Query
+		expected []string
+	}{
+		{
+			"all",
+			New(plan),
+			[]string{
+				"aws_instance.web[0]",
+				"aws_instance.web[1]",
+				"random_pet.name",
+				"module.db.aws_db_instance.main",
+				"module.db.module.backup.aws_s3_bucket.this",
+				"module.app.aws_instance.web",
+				"data.aws_ami.ubuntu",
+			},
+		},
+		{
+			"nil plan",
+			New(nil),
+			[]string{},
+		},
+		{
+			"replace",
+			New(plan).Actions(ActionReplace),
+			[]string{
+				"module.db.aws_db_instance.main",
+				"module.db.module.backup.aws_s3_bucket.this",
+			},
+		},
+		{
+			"create then delete",
+			New(plan).Actions(ActionCreateThenDelete),
+			[]string{"module.db.module.backup.aws_s3_bucket.this"},
+		},
+		{
+			"deletes",
+			New(plan).Actions(ActionDelete, ActionReplace),
+			[]string{
+				"module.db.aws_db_instance.main",
+				"module.db.module.backup.aws_s3_bucket.this",
+				"module.app.aws_instance.web",
+			},
+		},
+		{
+			"changed",
+			New(plan).Changed(),
+			[]string{
+				"aws_instance.web[0]",
+				"random_pet.name",
+				"module.db.aws_db_instance.main",
+				"module.db.module.backup.aws_s3_bucket.this",
+				"module.app.aws_instance.web",
+			},
+		},
+		{
+			"address glob",
+			New(plan).Address("aws_instance.web[*]"),
+			[]string{"aws_instance.web[0]", "aws_instance.web[1]"},
+		},
+		{
+			"address glob across modules",
+			New(plan).Address("*aws_instance.web*"),
+			[]string{"aws_instance.web[0]", "aws_instance.web[1]", "module.app.aws_instance.web"},
+		},
+		{
+			"address single character",
+			New(plan).Address("aws_instance.web[?]", "random_pet.name"),
+			[]string{"aws_instance.web[0]", "aws_instance.web[1]", "random_pet.name"},
+		},
+		{
+			"root module",
+			New(plan).Module("", false),
+			[]string{"aws_instance.web[0]", "aws_instance.web[1]", "random_pet.name", "data.aws_ami.ubuntu"},
+		},
+		{
+			"module",
+			New(plan).Module("module.db", false),
+			[]string{"module.db.aws_db_instance.main"},
+		},
+		{
+			"module recursive",
+			New(plan).Module("module.db", true),
+			[]string{"module.db.aws_db_instance.main", "module.db.module.backup.aws_s3_bucket.this"},
+		},
+		{
+			"provider",
+			New(plan).Provider("hashicorp/random"),
+			[]string{"random_pet.name"},
+		},
+		{
+			"provider fully qualified",
+			New(plan).Provider("registry.opentofu.org/hashicorp/random"),
+			[]string{"random_pet.name"},
+		},
+		{
+			"type",
+			New(plan).Type("aws_instance", "aws_s3_bucket"),
+			[]string{
+				"aws_instance.web[0]",
+				"aws_instance.web[1]",
+				"module.db.module.backup.aws_s3_bucket.this",
+				"module.app.aws_instance.web",
+			},
+		},
+		{
+			"mode",
+			New(plan).Mode(tfjson.DataResourceMode),
+			[]string{"data.aws_ami.ubuntu"},
+		},
+		{
+			"chained",
+			New(plan).Type("aws_instance").Actions(ActionCreate, ActionDelete).Module("", false),
+			[]string{"aws_instance.web[0]"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, c.query.Addresses()); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+			if c.query.Len() != len(c.expected) {
+				t.Fatalf("expected length %d, got %d", len(c.expected), c.query.Len())
+			}
+		})
+	}
+}
+
+func TestQuery_moduleInstances(t *testing.T) {
+	change := func(module string) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address:       module + ".aws_instance.x",
+			ModuleAddress: module,
+			Mode:          tfjson.ManagedResourceMode,
+			Type:          "aws_instance",
+			Change:        &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+		}
+	}
+
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			change("module.app"),
+			change("module.app[0]"),
+			change(`module.app["blue"]`),
+			change(`module.app["a]b"]`),
+			change(`module.app["blue"].module.db`),
+			change("module.application"),
+		},
+	}
+
+	for _, c := range []struct {
+		name     string
+		query    *Query
+		expected []string
+	}{
+		{
+			"every instance",
+			New(plan).Module("module.app", false),
+			[]string{
+				"module.app.aws_instance.x",
+				"module.app[0].aws_instance.x",
+				`module.app["blue"].aws_instance.x`,
+				`module.app["a]b"].aws_instance.x`,
+			},
+		},
+		{
+			"every instance recursive",
+			New(plan).Module("module.app", true),
+			[]string{
+				"module.app.aws_instance.x",
+				"module.app[0].aws_instance.x",
+				`module.app["blue"].aws_instance.x`,
+				`module.app["a]b"].aws_instance.x`,
+				`module.app["blue"].module.db.aws_instance.x`,
+			},
+		},
+		{
+			"count instance",
+			New(plan).Module("module.app[0]", true),
+			[]string{"module.app[0].aws_instance.x"},
+		},
+		{
+			"for_each instance",
+			New(plan).Module(`module.app["blue"]`, false),
+			[]string{`module.app["blue"].aws_instance.x`},
+		},
+		{
+			"for_each instance recursive",
+			New(plan).Module(`module.app["blue"]`, true),
+			[]string{`module.app["blue"].aws_instance.x`, `module.app["blue"].module.db.aws_instance.x`},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, c.query.Addresses()); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChangedAttributes(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		change   string
+		expected []AttributeChange
+	}{
+		{
+			"update",
+			`{
+				"actions": ["update"],
+				"before": {"id": "i-1", "instance_type": "t3.micro", "tags": {"Name": "web", "kubernetes.io/role": "a"}, "ports": [80, 443]},
+				"after": {"id": "i-1", "instance_type": "t3.small", "tags": {"Name": "web", "kubernetes.io/role": "b"}, "ports": [80, 8443]},
+				"after_unknown": {"tags": {}, "ports": [false, false]},
+				"before_sensitive": {"tags": {}, "ports": [false, false]},
+				"after_sensitive": {"tags": {}, "ports": [false, false]}
+			}`,
+			[]AttributeChange{
+				{Path: "instance_type", Before: "t3.micro", After: "t3.small"},
+				{Path: "ports[1]", Before: float64(443), After: float64(8443)},
+				{Path: `tags["kubernetes.io/role"]`, Before: "a", After: "b"},
+			},
+		},
+		{
+			"list length change",
+			`{
+				"actions": ["update"],
+				"before": {"ports": [80]},
+				"after": {"ports": [80, 443]},
+				"after_unknown": {},
+				"before_sensitive": {},
+				"after_sensitive": {}
+			}`,
+			[]AttributeChange{
+				{Path: "ports", Before: []interface{}{float64(80)}, After: []interface{}{float64(80), float64(443)}},
+			},
+		},
+		{
+			"sensitive",
+			`{
+				"actions": ["update"],
+				"before": {"password": "old", "user": "admin", "token": "same"},
+				"after": {"password": "new", "user": "admin", "token": "same"},
+				"after_unknown": {},
+				"before_sensitive": {"password": true},
+				"after_sensitive": {"password": true, "token": true}
+			}`,
+			[]AttributeChange{
+				{Path: "password", BeforeSensitive: true, AfterSensitive: true},
+				{Path: "token", Before: "same", AfterSensitive: true},
+			},
+		},
+		{
+			"unchanged sensitive",
+			`{
+				"actions": ["update"],
+				"before": {"password": "same", "size": 1},
+				"after": {"password": "same", "size": 2},
+				"after_unknown": {},
+				"before_sensitive": {"password": true},
+				"after_sensitive": {"password": true}
+			}`,
+			[]AttributeChange{
+				{Path: "size", Before: float64(1), After: float64(2)},
+			},
+		},
+		{
+			"create with unknown",
+			`{
+				"actions": ["create"],
+				"before": null,
+				"after": {"ami": "ami-123", "nested": {"a": 1}},
+				"after_unknown": {"id": true, "nested": {}},
+				"before_sensitive": false,
+				"after_sensitive": {"nested": {}}
+			}`,
+			[]AttributeChange{
+				{Path: "ami", After: "ami-123"},
+				{Path: "id", AfterUnknown: true},
+				{Path: "nested.a", After: float64(1)},
+			},
+		},
+		{
+			"delete",
+			`{
+				"actions": ["delete"],
+				"before": {"id": "abc"},
+				"after": null,
+				"after_unknown": {},
+				"before_sensitive": {},
+				"after_sensitive": false
+			}`,
+			[]AttributeChange{
+				{Path: "id", Before: "abc"},
+			},
+		},
+		{
+			"no-op",
+			`{
+				"actions": ["no-op"],
+				"before": {"id": "abc"},
+				"after": {"id": "abc"},
+				"after_unknown": {},
+				"before_sensitive": {},
+				"after_sensitive": {}
+			}`,
+			nil,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var change tfjson.Change
+			if err := json.Unmarshal([]byte(c.change), &change); err != nil {
+				t.Fatal(err)
+			}
+
+			actual := ChangedAttributes(&tfjson.ResourceChange{Change: &change})
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,554 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package planrender renders concise, human-readable summaries of plans, as
+// returned by (*tfexec.Tofu).ShowPlanFile, for use in pull request comments
+// and CI logs.
+package planrender
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+// Format is the output format of Render.
+type Format int
+
+const (
+	// FormatMarkdown renders GitHub flavoured Markdown, with the attribute
+	// changes of each resource in a collapsible section.
+	FormatMarkdown Format = iota
+
+	// FormatText renders plain text.
+	FormatText
+)
+
+// Options configures Render.
+type Options struct {
+	Format Format
+
+	// Title is rendered as a heading above the summary if set. It is escaped,
+	// so Markdown and HTML in the title are shown literally.
+	Title string
+
+	// MaxLength limits the length of the output in bytes. When the summary
+	// does not fit, trailing resource, drift and check entries are omitted
+	// and a note about the truncation is added. Zero means no limit.
+	//
+	// For example, GitHub limits comments to 65536 characters.
+	MaxLength int
+}
+
+const (
+	sensitiveValue = "(sensitive value)"
+	unknownValue   = "(known after apply)"
+)
+
+// Render returns a summary of the plan, consisting of the number of changes
+// grouped by resource type, the attribute changes of each resource, the
+// changes made outside of OpenTofu (drift) and any failed checks. Sensitive
+// values are masked.
+func Render(plan *tfjson.Plan, opts Options) string {
+	var r renderer = markdownRenderer{}
+	if opts.Format == FormatText {
+		r = textRenderer{}
+	}
+
+	var header strings.Builder
+	if opts.Title != "" {
+		header.WriteString(r.title(opts.Title))
+	}
+
+	changes := planquery.New(plan).Filter(func(rc *tfjson.ResourceChange) bool {
+		action := planquery.ActionOf(rc)
+		return action != planquery.ActionNoOp || isImport(rc)
+	}).ResourceChanges()
+
+	var drift []*tfjson.ResourceChange
+	if plan != nil {
+		drift = planquery.New(&tfjson.Plan{ResourceChanges: plan.ResourceDrift}).Changed().ResourceChanges()
+	}
+
+	failedChecks := failedChecks(plan)
+
+	if len(changes) == 0 {
+		header.WriteString(r.paragraph("No changes. Your infrastructure matches the configuration."))
+	} else {
+		header.WriteString(r.paragraph(summaryLine(changes)))
+		header.WriteString(r.table(countsTable(changes)))
+	}
+
+	var entries []entry
+	for _, rc := range changes {
+		entries = append(entries, entry{
+			section: "Resource changes",
+			text:    r.resource(rc, actionSymbol(rc), actionPhrase(rc), planquery.ChangedAttributes(rc)),
+		})
+	}
+	for _, rc := range drift {
+		entries = append(entries, entry{
+			section: "Changes outside of OpenTofu",
+			text:    r.resource(rc, actionSymbol(rc), driftPhrase(rc), planquery.ChangedAttributes(rc)),
+		})
+	}
+	for _, c := range failedChecks {
+		entries = append(entries, entry{
+			section: "Failed checks",
+			text:    r.check(c),
+		})
+	}
+
+	return assemble(r, header.String(), entries, opts.MaxLength)
+}
+
+type entry struct {
+	section string
+	text    string
+}
+
+// assemble joins the header and entries, omitting trailing entries so that
+// the output does not exceed maxLength.
+func assemble(r renderer, header string, entries []entry, maxLength int) string {
+	var b strings.Builder
+	b.WriteString(header)
+
+	// reserve room for the truncation note, which is longest when no entry
+	// is rendered
+	reserve := len(r.truncated(len(entries)))
+
+	section := ""
+	for i, e := range entries {
+		text := e.text
+		if e.section != section {
+			text = r.heading(e.section) + text
+		}
+
+		limit := maxLength
+		if i < len(entries)-1 {
+			limit -= reserve
+		}
+		if maxLength > 0 && b.Len()+len(text) > limit {
+			b.WriteString(r.truncated(len(entries) - i))
+			return truncate(b.String(), maxLength)
+		}
+
+		b.WriteString(text)
+		section = e.section
+	}
+
+	return truncate(b.String(), maxLength)
+}
+
+// truncate cuts s to at most maxLength bytes without splitting a UTF-8
+// character. This is only necessary if the header alone exceeds the limit.
+func truncate(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+	s = s[:maxLength]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func isImport(rc *tfjson.ResourceChange) bool {
+	return rc.Change != nil && rc.Change.Importing != nil
+}
+
+func summaryLine(changes []*tfjson.ResourceChange) string {
+	var add, change, destroy, imp int
+	for _, rc := range changes {
+		switch action := planquery.ActionOf(rc); {
+		case action.IsReplace():
+			add++
+			destroy++
+		case action == planquery.ActionCreate:
+			add++
+		case action == planquery.ActionUpdate:
+			change++
+		case action == planquery.ActionDelete:
+			destroy++
+		}
+		if isImport(rc) {
+			imp++
+		}
+	}
+
+	if imp > 0 {
+		return fmt.Sprintf("Plan: %d to import, %d to add, %d to change, %d to destroy.", imp, add, change, destroy)
+	}
+	return fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy.", add, change, destroy)
+}
+
+var countColumns = []string{"Resource type", "Create", "Update", "Delete", "Replace", "Import", "Read"}
+
+// countsTable returns the number of changes grouped by resource type.
+func countsTable(changes []*tfjson.ResourceChange) [][]string {
+	counts := map[string]*[6]int{}
+	var types []string
+	for _, rc := range changes {
+		c, ok := counts[rc.Type]
+		if !ok {
+			c = &[6]int{}
+			counts[rc.Type] = c
+			types = append(types, rc.Type)
+		}
+
+		switch action := planquery.ActionOf(rc); {
+		case action == planquery.ActionCreate:
+			c[0]++
+		case action == planquery.ActionUpdate:
+			c[1]++
+		case action == planquery.ActionDelete:
+			c[2]++
+		case action.IsReplace():
+			c[3]++
+		case action == planquery.ActionRead:
+			c[5]++
+		}
+		if isImport(rc) {
+			c[4]++
+		}
+	}
+	sort.Strings(types)
+
+	rows := [][]string{countColumns}
+	for _, t := range types {
+		row := []string{t}
+		for _, n := range counts[t] {
+			row = append(row, fmt.Sprint(n))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func actionSymbol(rc *tfjson.ResourceChange) string {
+	switch planquery.ActionOf(rc) {
+	case planquery.ActionCreate:
+		return "+"
+	case planquery.ActionUpdate:
+		return "~"
+	case planquery.ActionDelete:
+		return "-"
+	case planquery.ActionDeleteThenCreate:
+		return "-/+"
+	case planquery.ActionCreateThenDelete:
+		return "+/-"
+	case planquery.ActionRead:
+		return "<="
+	}
+	return " "
+}
+
+func actionPhrase(rc *tfjson.ResourceChange) string {
+	var phrase string
+	switch action := planquery.ActionOf(rc); {
+	case action == planquery.ActionCreate:
+		phrase = "will be created"
+	case action == planquery.ActionUpdate:
+		phrase = "will be updated in-place"
+	case action == planquery.ActionDelete:
+		phrase = "will be destroyed"
+	case action.IsReplace():
+		phrase = "must be replaced"
+	case action == planquery.ActionRead:
+		phrase = "will be read during apply"
+	case action == planquery.ActionNoOp:
+		return "will be imported"
+	default:
+		phrase = "will change"
+	}
+	if isImport(rc) {
+		phrase = "will be imported and " + strings.TrimPrefix(phrase, "will be ")
+	}
+	return phrase
+}
+
+func driftPhrase(rc *tfjson.ResourceChange) string {
+	if planquery.ActionOf(rc) == planquery.ActionDelete {
+		return "has been deleted"
+	}
+	return "has changed"
+}
+
+// failedCheck is a check which failed or errored, along with its problems.
+type failedCheck struct {
+	address  string
+	status   tfjson.CheckStatus
+	problems []string
+}
+
+func failedChecks(plan *tfjson.Plan) []failedCheck {
+	if plan == nil {
+		return nil
+	}
+
+	var failed []failedCheck
+	for _, c := range plan.Checks {
+		if c.Status != tfjson.CheckStatusFail && c.Status != tfjson.CheckStatusError {
+			continue
+		}
+		found := false
+		for _, i := range c.Instances {
+			if i.Status != tfjson.CheckStatusFail && i.Status != tfjson.CheckStatusError {
+				continue
+			}
+			found = true
+			fc := failedCheck{address: i.Address.ToDisplay, status: i.Status}
+			for _, p := range i.Problems {
+				fc.problems = append(fc.problems, p.Message)
+			}
+			failed = append(failed, fc)
+		}
+		if !found {
+			failed = append(failed, failedCheck{address: c.Address.ToDisplay, status: c.Status})
+		}
+	}
+	return failed
+}
+
+// attributeLine renders an attribute change in a style similar to the
+// OpenTofu CLI, masking sensitive values.
+func attributeLine(ac planquery.AttributeChange) string {
+	before := formatValue(ac.Before)
+	if ac.BeforeSensitive {
+		before = sensitiveValue
+	}
+	after := formatValue(ac.After)
+	switch {
+	case ac.AfterSensitive:
+		after = sensitiveValue
+	case ac.AfterUnknown:
+		after = unknownValue
+	}
+
+	switch {
+	case ac.Before == nil && !ac.BeforeSensitive:
+		return fmt.Sprintf("+ %s = %s", ac.Path, after)
+	case ac.After == nil && !ac.AfterSensitive && !ac.AfterUnknown:
+		return fmt.Sprintf("- %s = %s", ac.Path, before)
+	}
+	return fmt.Sprintf("~ %s = %s -> %s", ac.Path, before, after)
+}
+
+func formatValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+type renderer interface {
+	title(title string) string
+	heading(heading string) string
+	paragraph(text string) string
+	table(rows [][]string) string
+	resource(rc *tfjson.ResourceChange, symbol string, phrase string, attrs []planquery.AttributeChange) string
+	check(c failedCheck) string
+	truncated(omitted int) string
+}
+
+type markdownRenderer struct{}
+
+func (markdownRenderer) title(title string) string {
+	return "### " + escapeMarkdown(title) + "\n\n"
+}
+
+func (markdownRenderer) heading(heading string) string {
+	return "#### " + heading + "\n\n"
+}
+
+func (markdownRenderer) paragraph(text string) string {
+	return text + "\n\n"
+}
+
+func (markdownRenderer) table(rows [][]string) string {
+	var b strings.Builder
+	for i, row := range rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + escapeMarkdown(cell) + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString("|")
+			for j := range row {
+				if j == 0 {
+					b.WriteString(" --- |")
+				} else {
+					b.WriteString(" ---: |")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (markdownRenderer) resource(rc *tfjson.ResourceChange, symbol string, phrase string, attrs []planquery.AttributeChange) string {
+	lines := make([]string, len(attrs))
+	for i, ac := range attrs {
+		lines[i] = attributeLine(ac)
+	}
+	if len(lines) == 0 {
+		lines = []string{"# (no attribute changes)"}
+	}
+	body := strings.Join(lines, "\n")
+	fence := codeFence(body)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<details><summary><code>%s %s</code> %s</summary>\n\n",
+		html.EscapeString(symbol), html.EscapeString(rc.Address), html.EscapeString(phrase))
+	fmt.Fprintf(&b, "%sdiff\n%s\n%s\n\n", fence, body, fence)
+	b.WriteString("</details>\n\n")
+	return b.String()
+}
+
+func (markdownRenderer) check(c failedCheck) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- %s (%s)", codeSpan(c.address), c.status)
+	for _, p := range c.problems {
+		fmt.Fprintf(&b, "\n  - %s", escapeMarkdown(strings.ReplaceAll(p, "\n", " ")))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (markdownRenderer) truncated(omitted int) string {
+	return fmt.Sprintf("\n_Output truncated, %d more entries not shown._\n", omitted)
+}
+
+// markdownEscaper escapes the characters which Markdown or HTML would
+// interpret in inline text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"#", `\#`,
+	"|", `\|`,
+	"~", `\~`,
+	"!", `\!`,
+	"<", "&lt;",
+	">", "&gt;",
+	"&", "&amp;",
+)
+
+// escapeMarkdown escapes s for use as literal text in Markdown, such as in a
+// heading or table cell.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// codeSpan returns s as an inline code span, delimited by more backticks
+// than any run of backticks in s.
+func codeSpan(s string) string {
+	fence := strings.Repeat("`", longestBacktickRun(s)+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// codeFence returns a code fence longer than any run of backticks in s.
+func codeFence(s string) string {
+	longest := longestBacktickRun(s)
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+func longestBacktickRun(s string) int {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+type textRenderer struct{}
+
+func (textRenderer) title(title string) string {
+	return title + "\n" + strings.Repeat("=", utf8.RuneCountInString(title)) + "\n\n"
+}
+
+func (textRenderer) heading(heading string) string {
+	return heading + ":\n\n"
+}
+
+func (textRenderer) paragraph(text string) string {
+	return text + "\n\n"
+}
+
+func (textRenderer) table(rows [][]string) string {
+	widths := make([]int, len(countColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i == 0 {
+				cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			} else {
+				cells[i] = fmt.Sprintf("%*s", widths[i], cell)
+			}
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (textRenderer) resource(rc *tfjson.ResourceChange, symbol string, phrase string, attrs []planquery.AttributeChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s %s %s\n", symbol, rc.Address, phrase)
+	for _, ac := range attrs {
+		fmt.Fprintf(&b, "      %s\n", attributeLine(ac))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (textRenderer) check(c failedCheck) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  - %s (%s)\n", c.address, c.status)
+	for _, p := range c.problems {
+		fmt.Fprintf(&b, "      %s\n", strings.ReplaceAll(p, "\n", " "))
+	}
+	return b.String()
+}
+
+func (textRenderer) truncated(omitted int) string {
+	return fmt.Sprintf("\n... output truncated, %d more entries not shown.\n", omitted)
+}
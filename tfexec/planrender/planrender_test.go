@@ -0,0 +1,271 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planrender
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider_name": "registry.opentofu.org/hashicorp/aws",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": "i-1", "instance_type": "t3.micro", "password": "old"},
+        "after": {"id": "i-1", "instance_type": "t3.small", "password": "new"},
+        "after_unknown": {},
+        "before_sensitive": {"password": true},
+        "after_sensitive": {"password": true}
+      }
+    },
+    {
+      "address": "random_pet.name",
+      "mode": "managed",
+      "type": "random_pet",
+      "name": "name",
+      "provider_name": "registry.opentofu.org/hashicorp/random",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"length": 2},
+        "after_unknown": {"id": true},
+        "before_sensitive": false,
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "aws_instance.db",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "db",
+      "provider_name": "registry.opentofu.org/hashicorp/aws",
+      "change": {
+        "actions": ["delete", "create"],
+        "before": {"ami": "ami-1"},
+        "after": {"ami": "ami-2"},
+        "after_unknown": {},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "aws_instance.unchanged",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "unchanged",
+      "provider_name": "registry.opentofu.org/hashicorp/aws",
+      "change": {
+        "actions": ["no-op"],
+        "before": {"ami": "ami-1"},
+        "after": {"ami": "ami-1"},
+        "after_unknown": {},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      }
+    }
+  ],
+  "resource_drift": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider_name": "registry.opentofu.org/hashicorp/aws",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": "i-1", "tags": {"Owner": "a"}},
+        "after": {"id": "i-1", "tags": {"Owner": "b"}},
+        "after_unknown": {},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      }
+    }
+  ],
+  "checks": [
+    {
+      "address": {"kind": "check", "to_display": "check.health", "name": "health"},
+      "status": "fail",
+      "instances": [
+        {
+          "address": {"to_display": "check.health"},
+          "status": "fail",
+          "problems": [{"message": "Service is unhealthy"}]
+        }
+      ]
+    },
+    {
+      "address": {"kind": "output_value", "to_display": "output.url", "name": "url"},
+      "status": "pass"
+    }
+  ]
+}`
+
+func testPlan(t *testing.T) *tfjson.Plan {
+	t.Helper()
+
+	var plan tfjson.Plan
+	if err := json.Unmarshal([]byte(testPlanJSON), &plan); err != nil {
+		t.Fatal(err)
+	}
+	return &plan
+}
+
+func TestRender_markdown(t *testing.T) {
+	actual := Render(testPlan(t), Options{Title: "Plan for production"})
+
+	expected := "### Plan for production\n\n" +
+		"Plan: 2 to add, 1 to change, 1 to destroy.\n\n" +
+		"| Resource type | Create | Update | Delete | Replace | Import | Read |\n" +
+		"| --- | ---: | ---: | ---: | ---: | ---: | ---: |\n" +
+		"| aws\\_instance | 0 | 1 | 0 | 1 | 0 | 0 |\n" +
+		"| random\\_pet | 1 | 0 | 0 | 0 | 0 | 0 |\n\n" +
+		"#### Resource changes\n\n" +
+		"<details><summary><code>~ aws_instance.web</code> will be updated in-place</summary>\n\n" +
+		"```diff\n" +
+		"~ instance_type = \"t3.micro\" -> \"t3.small\"\n" +
+		"~ password = (sensitive value) -> (sensitive value)\n" +
+		"```\n\n" +
+		"</details>\n\n" +
+		"<details><summary><code>+ random_pet.name</code> will be created</summary>\n\n" +
+		"```diff\n" +
+		"+ id = (known after apply)\n" +
+		"+ length = 2\n" +
+		"```\n\n" +
+		"</details>\n\n" +
+		"<details><summary><code>-/+ aws_instance.db</code> must be replaced</summary>\n\n" +
+		"```diff\n" +
+		"~ ami = \"ami-1\" -> \"ami-2\"\n" +
+		"```\n\n" +
+		"</details>\n\n" +
+		"#### Changes outside of OpenTofu\n\n" +
+		"<details><summary><code>~ aws_instance.web</code> has changed</summary>\n\n" +
+		"```diff\n" +
+		"~ tags.Owner = \"a\" -> \"b\"\n" +
+		"```\n\n" +
+		"</details>\n\n" +
+		"#### Failed checks\n\n" +
+		"- `check.health` (fail)\n" +
+		"  - Service is unhealthy\n"
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if strings.Contains(actual, "old") || strings.Contains(actual, "new") {
+		t.Fatal("expected sensitive values to be masked")
+	}
+}
+
+func TestRender_text(t *testing.T) {
+	actual := Render(testPlan(t), Options{Format: FormatText})
+
+	expected := "Plan: 2 to add, 1 to change, 1 to destroy.\n\n" +
+		"Resource type  Create  Update  Delete  Replace  Import  Read\n" +
+		"aws_instance        0       1       0        1       0     0\n" +
+		"random_pet          1       0       0        0       0     0\n\n" +
+		"Resource changes:\n\n" +
+		"  ~ aws_instance.web will be updated in-place\n" +
+		"      ~ instance_type = \"t3.micro\" -> \"t3.small\"\n" +
+		"      ~ password = (sensitive value) -> (sensitive value)\n\n" +
+		"  + random_pet.name will be created\n" +
+		"      + id = (known after apply)\n" +
+		"      + length = 2\n\n" +
+		"  -/+ aws_instance.db must be replaced\n" +
+		"      ~ ami = \"ami-1\" -> \"ami-2\"\n\n" +
+		"Changes outside of OpenTofu:\n\n" +
+		"  ~ aws_instance.web has changed\n" +
+		"      ~ tags.Owner = \"a\" -> \"b\"\n\n" +
+		"Failed checks:\n\n" +
+		"  - check.health (fail)\n" +
+		"      Service is unhealthy\n"
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRender_markdownEscaping(t *testing.T) {
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: `null_resource.foo["<img src=x onerror=alert(1)>"]`,
+				Type:    "null_resource",
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+			},
+		},
+		Checks: []tfjson.CheckResultStatic{
+			{
+				Address: tfjson.CheckStaticAddress{ToDisplay: "check.a`b"},
+				Status:  tfjson.CheckStatusFail,
+				Instances: []tfjson.CheckResultDynamic{
+					{
+						Address:  tfjson.CheckDynamicAddress{ToDisplay: "check.a`b"},
+						Status:   tfjson.CheckStatusFail,
+						Problems: []tfjson.CheckResultProblem{{Message: "see [here](https://example.com) <b>now</b>"}},
+					},
+				},
+			},
+		},
+	}
+
+	actual := Render(plan, Options{Title: "Plan for *prod* <script>"})
+
+	for _, expected := range []string{
+		"### Plan for \\*prod\\* &lt;script&gt;\n",
+		"<code>+ null_resource.foo[&#34;&lt;img src=x onerror=alert(1)&gt;&#34;]</code>",
+		"- ``check.a`b`` (fail)\n",
+		"  - see \\[here\\](https://example.com) &lt;b&gt;now&lt;/b&gt;\n",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Fatalf("expected output to contain %q, got:\n%s", expected, actual)
+		}
+	}
+	if strings.Contains(actual, "<script>") || strings.Contains(actual, "<img") || strings.Contains(actual, "<b>") {
+		t.Fatalf("expected HTML to be escaped, got:\n%s", actual)
+	}
+}
+
+func TestRender_noChanges(t *testing.T) {
+	actual := Render(&tfjson.Plan{}, Options{Format: FormatText})
+
+	expected := "No changes. Your infrastructure matches the configuration.\n\n"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestRender_maxLength(t *testing.T) {
+	plan := testPlan(t)
+	full := Render(plan, Options{})
+
+	if actual := Render(plan, Options{MaxLength: len(full)}); actual != full {
+		t.Fatalf("expected output of exactly MaxLength to be complete, got:\n%s", actual)
+	}
+
+	for _, maxLength := range []int{len(full) - 1, 600, 300, 10} {
+		actual := Render(plan, Options{MaxLength: maxLength})
+		if len(actual) > maxLength {
+			t.Fatalf("expected at most %d bytes, got %d", maxLength, len(actual))
+		}
+		if maxLength >= 300 && !strings.Contains(actual, "Output truncated") {
+			t.Fatalf("expected truncation note for MaxLength %d, got:\n%s", maxLength, actual)
+		}
+		if strings.Count(actual, "<details>") != strings.Count(actual, "</details>") {
+			t.Fatalf("expected only complete entries for MaxLength %d, got:\n%s", maxLength, actual)
+		}
+	}
+}
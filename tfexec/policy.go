@@ -0,0 +1,180 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+// PolicyInput is the input of a Policy.
+type PolicyInput struct {
+	// Plan is the saved plan about to be applied.
+	Plan *tfjson.Plan
+
+	// Workspace is the currently selected workspace.
+	Workspace string
+}
+
+// PolicyViolation describes a violation of a Policy.
+type PolicyViolation struct {
+	// Policy is the name of the violated policy.
+	Policy string
+
+	// Address and Action describe the violating resource change. Address is
+	// empty if the violation concerns the plan as a whole.
+	Address string
+	Action  planquery.Action
+
+	Message string
+}
+
+// Policy is a guardrail evaluated against a saved plan before it is applied,
+// see the Policies option.
+type Policy interface {
+	// Name identifies the policy in violations.
+	Name() string
+
+	// Evaluate returns the violations of the policy by the plan. An error
+	// means the policy could not be evaluated and also prevents the apply.
+	Evaluate(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error)
+}
+
+type policyFunc struct {
+	name string
+	fn   func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error)
+}
+
+func (p *policyFunc) Name() string {
+	return p.name
+}
+
+func (p *policyFunc) Evaluate(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+	violations, err := p.fn(ctx, input)
+	for i := range violations {
+		if violations[i].Policy == "" {
+			violations[i].Policy = p.name
+		}
+	}
+	return violations, err
+}
+
+// PolicyFunc returns a Policy with the given name evaluated by fn. The
+// Policy field of violations returned by fn defaults to name.
+func PolicyFunc(name string, fn func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error)) Policy {
+	return &policyFunc{name: name, fn: fn}
+}
+
+// DenyDestroy returns a Policy which forbids destroying resources of the
+// given types, including by replacing them. Without types, destroying any
+// resource is forbidden.
+func DenyDestroy(types ...string) Policy {
+	name := "deny-destroy"
+	return PolicyFunc(name, func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+		q := planquery.New(input.Plan).Actions(planquery.ActionDelete, planquery.ActionReplace)
+		if len(types) > 0 {
+			q = q.Type(types...)
+		}
+		return violationsFor(q, "%s must not be destroyed"), nil
+	})
+}
+
+// DenyReplace returns a Policy which forbids replacing resources.
+func DenyReplace() Policy {
+	return PolicyFunc("deny-replace", func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+		q := planquery.New(input.Plan).Actions(planquery.ActionReplace)
+		return violationsFor(q, "%s must not be replaced"), nil
+	})
+}
+
+// MaxDeletions returns a Policy which forbids plans destroying more than max
+// resources, including by replacing them. Every destroyed resource is
+// reported as a violation.
+func MaxDeletions(max int) Policy {
+	return PolicyFunc("max-deletions", func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+		q := planquery.New(input.Plan).Actions(planquery.ActionDelete, planquery.ActionReplace)
+		if q.Len() <= max {
+			return nil, nil
+		}
+		format := fmt.Sprintf("%%s is one of %d resources destroyed by the plan, at most %d are allowed", q.Len(), max)
+		return violationsFor(q, format), nil
+	})
+}
+
+// InWorkspaces returns a Policy which only evaluates policy if one of the
+// given workspaces is selected, for example to forbid replacing resources in
+// production only:
+//
+//	InWorkspaces(DenyReplace(), "prod")
+func InWorkspaces(policy Policy, workspaces ...string) Policy {
+	return PolicyFunc(policy.Name(), func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+		for _, w := range workspaces {
+			if w == input.Workspace {
+				return policy.Evaluate(ctx, input)
+			}
+		}
+		return nil, nil
+	})
+}
+
+func violationsFor(q *planquery.Query, format string) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, rc := range q.ResourceChanges() {
+		violations = append(violations, PolicyViolation{
+			Address: rc.Address,
+			Action:  planquery.ActionOf(rc),
+			Message: fmt.Sprintf(format, rc.Address),
+		})
+	}
+	return violations
+}
+
+// checkPolicies evaluates the policies against the saved plan and returns a
+// *PolicyViolationError if any of them is violated.
+func (tf *Tofu) checkPolicies(ctx context.Context, policies []Policy, planFile string, reattachInfo ReattachInfo) error {
+	if planFile == "" {
+		return fmt.Errorf("the Policies option requires a saved plan passed with the DirOrPlan option")
+	}
+
+	ctx = withoutResult(ctx)
+
+	var showOpts []ShowOption
+	if reattachInfo != nil {
+		showOpts = append(showOpts, Reattach(reattachInfo))
+	}
+	plan, err := tf.ShowPlanFile(ctx, planFile, showOpts...)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := tf.WorkspaceShow(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := &PolicyInput{
+		Plan:      plan,
+		Workspace: workspace,
+	}
+
+	var violations []PolicyViolation
+	for _, p := range policies {
+		v, err := p.Evaluate(ctx, input)
+		if err != nil {
+			return fmt.Errorf("unable to evaluate policy %s: %w", p.Name(), err)
+		}
+		violations = append(violations, v...)
+	}
+
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/opentofu/tofu-exec/tfexec/planquery"
+)
+
+func policyTestPlan() *tfjson.Plan {
+	change := func(address string, typ string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    typ,
+			Change:  &tfjson.Change{Actions: actions},
+		}
+	}
+	return &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			change("aws_instance.web", "aws_instance", tfjson.ActionDelete, tfjson.ActionCreate),
+			change("aws_db_instance.main", "aws_db_instance", tfjson.ActionDelete),
+			change("aws_s3_bucket.logs", "aws_s3_bucket", tfjson.ActionUpdate),
+			change("null_resource.a", "null_resource", tfjson.ActionCreate),
+		},
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	input := &PolicyInput{Plan: policyTestPlan(), Workspace: "prod"}
+
+	for _, c := range []struct {
+		name     string
+		policy   Policy
+		expected []PolicyViolation
+	}{
+		{
+			"deny destroy of type",
+			DenyDestroy("aws_db_instance"),
+			[]PolicyViolation{
+				{Policy: "deny-destroy", Address: "aws_db_instance.main", Action: planquery.ActionDelete, Message: "aws_db_instance.main must not be destroyed"},
+			},
+		},
+		{
+			"deny destroy of any type",
+			DenyDestroy(),
+			[]PolicyViolation{
+				{Policy: "deny-destroy", Address: "aws_instance.web", Action: planquery.ActionDeleteThenCreate, Message: "aws_instance.web must not be destroyed"},
+				{Policy: "deny-destroy", Address: "aws_db_instance.main", Action: planquery.ActionDelete, Message: "aws_db_instance.main must not be destroyed"},
+			},
+		},
+		{
+			"deny replace",
+			DenyReplace(),
+			[]PolicyViolation{
+				{Policy: "deny-replace", Address: "aws_instance.web", Action: planquery.ActionDeleteThenCreate, Message: "aws_instance.web must not be replaced"},
+			},
+		},
+		{
+			"max deletions within limit",
+			MaxDeletions(2),
+			nil,
+		},
+		{
+			"max deletions exceeded",
+			MaxDeletions(1),
+			[]PolicyViolation{
+				{Policy: "max-deletions", Address: "aws_instance.web", Action: planquery.ActionDeleteThenCreate, Message: "aws_instance.web is one of 2 resources destroyed by the plan, at most 1 are allowed"},
+				{Policy: "max-deletions", Address: "aws_db_instance.main", Action: planquery.ActionDelete, Message: "aws_db_instance.main is one of 2 resources destroyed by the plan, at most 1 are allowed"},
+			},
+		},
+		{
+			"in selected workspace",
+			InWorkspaces(DenyReplace(), "staging", "prod"),
+			[]PolicyViolation{
+				{Policy: "deny-replace", Address: "aws_instance.web", Action: planquery.ActionDeleteThenCreate, Message: "aws_instance.web must not be replaced"},
+			},
+		},
+		{
+			"in other workspace",
+			InWorkspaces(DenyReplace(), "staging"),
+			nil,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := c.policy.Evaluate(context.Background(), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPolicyFunc(t *testing.T) {
+	p := PolicyFunc("custom", func(ctx context.Context, input *PolicyInput) ([]PolicyViolation, error) {
+		return []PolicyViolation{
+			{Message: "default name"},
+			{Policy: "other", Message: "explicit name"},
+		}, nil
+	})
+
+	if p.Name() != "custom" {
+		t.Fatalf("expected name custom, got %q", p.Name())
+	}
+
+	actual, err := p.Evaluate(context.Background(), &PolicyInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PolicyViolation{
+		{Policy: "custom", Message: "default name"},
+		{Policy: "other", Message: "explicit name"},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPolicyViolationError(t *testing.T) {
+	var err error = &PolicyViolationError{
+		Violations: []PolicyViolation{
+			{Policy: "deny-replace", Address: "aws_instance.web", Message: "aws_instance.web must not be replaced"},
+			{Policy: "max-deletions", Address: "aws_db_instance.main", Message: "aws_db_instance.main is one of 2 resources destroyed by the plan, at most 1 are allowed"},
+		},
+	}
+
+	if !errors.Is(err, ErrPolicyViolation) {
+		t.Fatal("expected error to match ErrPolicyViolation")
+	}
+
+	expected := "plan violates 2 policy rule(s):\n" +
+		"  - deny-replace: aws_instance.web must not be replaced\n" +
+		"  - max-deletions: aws_db_instance.main is one of 2 resources destroyed by the plan, at most 1 are allowed"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
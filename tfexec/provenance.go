@@ -0,0 +1,203 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// PlanProvenance records the circumstances under which a saved plan was
+// created, so that Apply can detect a plan file which was swapped or which
+// no longer matches the state.
+//
+// It is written as JSON next to the plan file, see PlanProvenancePath.
+type PlanProvenance struct {
+	// PlanChecksum is the hex encoded SHA-256 checksum of the plan file.
+	PlanChecksum string `json:"plan_checksum"`
+
+	// StateSerial and StateLineage identify the state the plan was created
+	// against. Both are zero values if there was no state yet.
+	StateSerial  uint64 `json:"state_serial"`
+	StateLineage string `json:"state_lineage"`
+
+	Workspace   string    `json:"workspace"`
+	TofuVersion string    `json:"tofu_version"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PlanProvenancePath returns the path of the provenance record of a plan
+// file.
+func PlanProvenancePath(planFile string) string {
+	return planFile + ".provenance.json"
+}
+
+// ReadPlanProvenance reads the provenance record of a plan file. Relative
+// paths are resolved against the working directory.
+func (tf *Tofu) ReadPlanProvenance(planFile string) (*PlanProvenance, error) {
+	b, err := os.ReadFile(PlanProvenancePath(tf.resolvePath(planFile)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plan provenance: %w", err)
+	}
+
+	var p PlanProvenance
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse plan provenance: %w", err)
+	}
+	return &p, nil
+}
+
+// resolvePath resolves a path relative to the working directory, as the
+// commands do.
+func (tf *Tofu) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(tf.workingDir, path)
+}
+
+// writePlanProvenance records the provenance of a plan file created with the
+// given State option.
+func (tf *Tofu) writePlanProvenance(ctx context.Context, planFile string, statePath string) error {
+	p, err := tf.currentProvenance(ctx, planFile, statePath)
+	if err != nil {
+		return fmt.Errorf("unable to record plan provenance: %w", err)
+	}
+	p.CreatedAt = time.Now().UTC()
+
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(PlanProvenancePath(tf.resolvePath(planFile)), b, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to record plan provenance: %w", err)
+	}
+	return nil
+}
+
+// verifyPlanProvenance returns a *StalePlanError if the plan file or the
+// state changed since the provenance of the plan file was recorded.
+func (tf *Tofu) verifyPlanProvenance(ctx context.Context, planFile string, statePath string) error {
+	recorded, err := tf.ReadPlanProvenance(planFile)
+	if err != nil {
+		return err
+	}
+
+	current, err := tf.currentProvenance(ctx, planFile, statePath)
+	if err != nil {
+		return fmt.Errorf("unable to verify plan provenance: %w", err)
+	}
+
+	var mismatches []ProvenanceMismatch
+	compare := func(field string, recorded string, current string) {
+		if recorded != current {
+			mismatches = append(mismatches, ProvenanceMismatch{
+				Field:    field,
+				Recorded: recorded,
+				Current:  current,
+			})
+		}
+	}
+	compare("plan_checksum", recorded.PlanChecksum, current.PlanChecksum)
+	compare("state_lineage", recorded.StateLineage, current.StateLineage)
+	compare("state_serial", strconv.FormatUint(recorded.StateSerial, 10), strconv.FormatUint(current.StateSerial, 10))
+	compare("workspace", recorded.Workspace, current.Workspace)
+	compare("tofu_version", recorded.TofuVersion, current.TofuVersion)
+
+	if len(mismatches) > 0 {
+		return &StalePlanError{
+			PlanFile:   planFile,
+			Mismatches: mismatches,
+		}
+	}
+	return nil
+}
+
+func (tf *Tofu) currentProvenance(ctx context.Context, planFile string, statePath string) (*PlanProvenance, error) {
+	ctx = withoutResult(ctx)
+
+	checksum, err := fileChecksum(tf.resolvePath(planFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// the State option bypasses the backend, so read the state file directly
+	var state []byte
+	if statePath != "" {
+		state, err = os.ReadFile(tf.resolvePath(statePath))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		pulled, err := tf.StatePull(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state = []byte(pulled)
+	}
+
+	p := &PlanProvenance{PlanChecksum: checksum}
+	if len(state) > 0 {
+		p.StateSerial, p.StateLineage, err = parseStateMeta(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.Workspace, err = tf.WorkspaceShow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := tf.Version(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	p.TofuVersion = v.String()
+
+	return p, nil
+}
+
+// parseStateMeta returns the serial and lineage of a state file. Every state
+// written by OpenTofu has a lineage, so a state without one, such as an
+// encrypted state file, is an error rather than an empty state.
+func parseStateMeta(state []byte) (uint64, string, error) {
+	var meta struct {
+		Serial  uint64 `json:"serial"`
+		Lineage string `json:"lineage"`
+	}
+	if err := json.Unmarshal(state, &meta); err != nil {
+		return 0, "", fmt.Errorf("unable to parse state: %w", err)
+	}
+	if meta.Lineage == "" {
+		return 0, "", fmt.Errorf("unable to parse state: no lineage found, the state may be encrypted")
+	}
+	return meta.Serial, meta.Lineage, nil
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
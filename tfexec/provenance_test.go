@@ -0,0 +1,88 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadPlanProvenance(t *testing.T) {
+	td := t.TempDir()
+	tf := &Tofu{workingDir: td}
+
+	err := os.WriteFile(filepath.Join(td, "plan.tfplan.provenance.json"), []byte(`{
+  "plan_checksum": "abc",
+  "state_serial": 4,
+  "state_lineage": "5b6c",
+  "workspace": "default",
+  "tofu_version": "1.10.5",
+  "created_at": "2024-01-02T03:04:05Z"
+}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := tf.ReadPlanProvenance("plan.tfplan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &PlanProvenance{
+		PlanChecksum: "abc",
+		StateSerial:  4,
+		StateLineage: "5b6c",
+		Workspace:    "default",
+		TofuVersion:  "1.10.5",
+		CreatedAt:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStalePlanError(t *testing.T) {
+	var err error = &StalePlanError{
+		PlanFile: "plan.tfplan",
+		Mismatches: []ProvenanceMismatch{
+			{Field: "state_serial", Recorded: "4", Current: "5"},
+			{Field: "workspace", Recorded: "default", Current: "prod"},
+		},
+	}
+
+	expected := `saved plan plan.tfplan is stale: state_serial changed from "4" to "5", workspace changed from "default" to "prod"`
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+	if !errors.Is(err, ErrStalePlan) {
+		t.Fatal("expected error to match ErrStalePlan")
+	}
+}
+
+func TestParseStateMeta(t *testing.T) {
+	serial, lineage, err := parseStateMeta([]byte(`{"version": 4, "serial": 7, "lineage": "5b6c"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial != 7 || lineage != "5b6c" {
+		t.Fatalf("expected serial 7 and lineage %q, got %d and %q", "5b6c", serial, lineage)
+	}
+
+	for _, state := range []string{
+		`{"serial": 1, "lineage": "5b6c"`,
+		`{"meta": {"key_provider.pbkdf2.main": "e30="}, "encrypted_data": "c2VjcmV0", "encryption_version": "v0"}`,
+	} {
+		_, _, err := parseStateMeta([]byte(state))
+		if err == nil {
+			t.Fatalf("expected error parsing %s, got none", state)
+		}
+	}
+}
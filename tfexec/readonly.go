@@ -0,0 +1,89 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"strings"
+)
+
+// mutatingCommands lists the commands which can change infrastructure or
+// state, and are therefore refused in read-only mode.
+var mutatingCommands = [][]string{
+	{"apply"},
+	{"destroy"},
+	{"force-unlock"},
+	{"import"},
+	{"refresh"},
+	{"state", "mv"},
+	{"state", "push"},
+	{"state", "replace-provider"},
+	{"state", "rm"},
+	{"taint"},
+	{"test"},
+	{"untaint"},
+	{"workspace", "delete"},
+	{"workspace", "new"},
+}
+
+// mutatingFlags lists the flags which make an otherwise read-only command
+// change the state, keyed by command.
+var mutatingFlags = map[string][]string{
+	"init": {"-force-copy", "-migrate-state"},
+}
+
+// SetReadOnly enables or disables read-only mode. In read-only mode, commands
+// which can change infrastructure or state, such as Apply, Destroy, Import,
+// Refresh, Test, the state and taint commands, ForceUnlock, WorkspaceNew,
+// WorkspaceDelete and Init with state migration, are refused with a *ReadOnlyError matching ErrReadOnly,
+// and plans are run without locking the state.
+func (tf *Tofu) SetReadOnly(readOnly bool) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.readOnly = readOnly
+}
+
+// readOnlyArgs returns the arguments of a command run in read-only mode, or a
+// *ReadOnlyError if the command is refused.
+func readOnlyArgs(args []string) ([]string, error) {
+	for _, command := range mutatingCommands {
+		if len(args) >= len(command) && equalArgs(args[:len(command)], command) {
+			return nil, &ReadOnlyError{Command: strings.Join(command, " ")}
+		}
+	}
+
+	if len(args) > 0 {
+		for _, flag := range mutatingFlags[args[0]] {
+			for _, arg := range args[1:] {
+				if arg == flag || arg == flag+"=true" {
+					return nil, &ReadOnlyError{Command: args[0] + " " + flag}
+				}
+			}
+		}
+	}
+
+	if len(args) > 0 && args[0] == "plan" {
+		// plans never write the state, so they need not lock it
+		readOnly := make([]string, len(args))
+		for i, arg := range args {
+			if strings.HasPrefix(arg, "-lock=") {
+				arg = "-lock=false"
+			}
+			readOnly[i] = arg
+		}
+		return readOnly, nil
+	}
+
+	return args, nil
+}
+
+func equalArgs(a []string, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadOnlyArgs(t *testing.T) {
+	for _, c := range []struct {
+		args    []string
+		refused string
+		want    []string
+	}{
+		{args: []string{"apply", "-no-color", "-auto-approve"}, refused: "apply"},
+		{args: []string{"destroy", "-no-color"}, refused: "destroy"},
+		{args: []string{"state", "rm", "-no-color", "foo.bar"}, refused: "state rm"},
+		{args: []string{"workspace", "delete", "-no-color", "dev"}, refused: "workspace delete"},
+		{args: []string{"force-unlock", "-no-color", "-force", "id"}, refused: "force-unlock"},
+		{args: []string{"init", "-no-color", "-input=false", "-force-copy"}, refused: "init -force-copy"},
+		{args: []string{"init", "-no-color", "-migrate-state"}, refused: "init -migrate-state"},
+		{args: []string{"init", "-no-color", "-reconfigure"}, want: []string{"init", "-no-color", "-reconfigure"}},
+		{args: []string{"state", "list", "-no-color"}, want: []string{"state", "list", "-no-color"}},
+		{args: []string{"workspace", "select", "-no-color", "dev"}, want: []string{"workspace", "select", "-no-color", "dev"}},
+		{args: []string{"show", "-json", "-no-color"}, want: []string{"show", "-json", "-no-color"}},
+		{
+			args: []string{"plan", "-no-color", "-lock-timeout=0s", "-lock=true", "-parallelism=10"},
+			want: []string{"plan", "-no-color", "-lock-timeout=0s", "-lock=false", "-parallelism=10"},
+		},
+	} {
+		t.Run(strings.Join(c.args, " "), func(t *testing.T) {
+			actual, err := readOnlyArgs(c.args)
+			if c.refused != "" {
+				var roErr *ReadOnlyError
+				if !errors.As(err, &roErr) {
+					t.Fatalf("expected ReadOnlyError, got %v", err)
+				}
+				if roErr.Command != c.refused {
+					t.Fatalf("expected refused command %q, got %q", c.refused, roErr.Command)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, actual); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetReadOnly(t *testing.T) {
+	tf := &Tofu{
+		logger:   log.New(io.Discard, "", 0),
+		execPath: "/usr/local/bin/tofu",
+	}
+	tf.SetReadOnly(true)
+
+	tracer := &memoryTracer{}
+	tf.SetTracer(tracer)
+	hookCalls := 0
+	tf.AddHook(func(next RunFunc) RunFunc {
+		return func(ctx context.Context, c *Command) error {
+			hookCalls++
+			return next(ctx, c)
+		}
+	})
+
+	var result RunResult
+	err := tf.Apply(WithResult(context.Background(), &result))
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if hookCalls != 0 || len(tracer.spans) != 0 || result.Args != nil {
+		t.Fatalf("expected refused command to bypass hooks, tracing and results, got %d hook calls, %d spans and %+v",
+			hookCalls, len(tracer.spans), result)
+	}
+
+	err = tf.StateRm(context.Background(), "foo.bar")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+
+	planCmd, err := tf.planCmd(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errors.Is(planCmd.Err, ErrReadOnly) {
+		t.Fatal("expected plan to be allowed")
+	}
+
+	if !slices.Contains(planCmd.Args, "-lock=false") {
+		t.Fatalf("expected plan not to lock the state, got %v", planCmd.Args)
+	}
+
+	tf.SetReadOnly(false)
+
+	applyCmd, err := tf.applyCmd(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errors.Is(applyCmd.Err, ErrReadOnly) {
+		t.Fatal("expected apply to be allowed after disabling read-only mode")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// redactedValue replaces secrets in logs, error messages and captured output.
+const redactedValue = "[REDACTED]"
+
+// minSecretLength is the minimum length of values of sensitive flags and
+// environment variables to redact, as redacting short values such as "dev"
+// would mangle unrelated text.
+const minSecretLength = 4
+
+// sensitiveEnvVars, sensitiveEnvVarPrefixes and sensitiveEnvVarSubstrings
+// match the names of environment variables whose values are redacted.
+var (
+	sensitiveEnvVars = []string{
+		encryptionEnvVar,
+	}
+	sensitiveEnvVarPrefixes = []string{
+		"TF_TOKEN_",
+		varEnvVarPrefix,
+	}
+	sensitiveEnvVarSubstrings = []string{
+		"API_KEY",
+		"PASSPHRASE",
+		"PASSWORD",
+		"PRIVATE_KEY",
+		"SECRET",
+		"TOKEN",
+	}
+)
+
+// AddSecrets registers strings to redact from the log, error messages and
+// captured output such as RunResult. Secrets are only redacted where they
+// occur as a whole word, not as part of a longer one.
+//
+// The values of Var and VarValue options are redacted from the command line
+// only, use SensitiveVar or register them for secrets. The values of
+// SensitiveVar and BackendConfig options, of sensitive environment variables
+// such as TF_TOKEN_* or AWS_SECRET_ACCESS_KEY, and the encryption
+// configuration in TF_ENCRYPTION, including the passphrases and keys it
+// contains, are redacted without registering them. The strings within complex
+// variable values, such as the attributes of an object, are redacted on their
+// own. Numbers and bools among these values are not redacted, nor are
+// TF_VAR_* variables inherited from the environment of the calling process.
+//
+// Output streamed to the writers set with SetStdout, SetStderr or WithOutput
+// is not redacted.
+func (tf *Tofu) AddSecrets(secrets ...string) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	for _, s := range secrets {
+		if s != "" {
+			tf.secrets = append(tf.secrets, s)
+		}
+	}
+}
+
+// redactor redacts the secrets of a command.
+type redactor struct {
+	// secrets are sorted longest first, so that a secret containing another
+	// one is redacted as a whole.
+	secrets []string
+}
+
+// newRedactor returns a redactor for the registered secrets, and the values of
+// sensitive flags and sensitive environment variables of a command.
+func newRedactor(secrets []string, args []string, env []string) *redactor {
+	r := &redactor{secrets: append([]string(nil), secrets...)}
+	add := func(values ...string) {
+		for _, v := range values {
+			if len(v) >= minSecretLength && !isScalarValue(v) {
+				r.secrets = append(r.secrets, v)
+			}
+		}
+	}
+
+	for i, arg := range args {
+		// -var values are only redacted from the command line, as most are
+		// not secret, while backend configuration usually is
+		if value, ok := sensitiveFlagValue(args, i); ok && strings.HasPrefix(arg, "-backend-config=") {
+			add(value)
+		}
+	}
+	for k, v := range envMap(env) {
+		if !isSensitiveEnvVar(k) {
+			continue
+		}
+		if strings.HasPrefix(k, varEnvVarPrefix) {
+			// variables set for the calling process are configuration
+			// rather than SensitiveVar values
+			if inherited, ok := os.LookupEnv(k); ok && inherited == v {
+				continue
+			}
+		}
+		add(v)
+		switch {
+		case strings.HasPrefix(k, varEnvVarPrefix):
+			add(varValueStrings(v)...)
+		case k == encryptionEnvVar:
+			add(encryptionSecrets(v)...)
+		}
+	}
+	sort.SliceStable(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+	return r
+}
+
+// redactor returns the redactor for a command.
+func (tf *Tofu) redactor(cmd *exec.Cmd) *redactor {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+	return newRedactor(tf.secrets, cmd.Args[1:], cmd.Env)
+}
+
+// isScalarValue reports whether a value is a number or a bool, which are not
+// redacted as they are unlikely to be secret but likely to occur elsewhere.
+func isScalarValue(value string) bool {
+	if value == "true" || value == "false" {
+		return true
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func isSensitiveEnvVar(name string) bool {
+	name = strings.ToUpper(name)
+	for _, n := range sensitiveEnvVars {
+		if name == n {
+			return true
+		}
+	}
+	for _, p := range sensitiveEnvVarPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	for _, s := range sensitiveEnvVarSubstrings {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces every secret occurring as a whole word in s.
+func (r *redactor) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = replaceWord(s, secret)
+	}
+	return s
+}
+
+// replaceWord replaces the occurrences of secret in s which are not part of
+// a longer word.
+func replaceWord(s string, secret string) string {
+	first, _ := utf8.DecodeRuneInString(secret)
+	last, _ := utf8.DecodeLastRuneInString(secret)
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, secret)
+		if i < 0 {
+			break
+		}
+		end := i + len(secret)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if isWordRune(before) && isWordRune(first) || isWordRune(after) && isWordRune(last) {
+			b.WriteString(s[:i+1])
+			s = s[i+1:]
+			continue
+		}
+		b.WriteString(s[:i])
+		b.WriteString(redactedValue)
+		s = s[end:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (r *redactor) redactBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return []byte(r.redact(string(b)))
+}
+
+// redactArgs redacts the values of sensitive flags, such as -var and
+// -backend-config, as well as any secrets in the arguments of a command.
+func (r *redactor) redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if value, ok := sensitiveFlagValue(args, i); ok {
+			arg = strings.TrimSuffix(arg, value) + redactedValue
+		}
+		redacted[i] = r.redact(arg)
+	}
+	return redacted
+}
+
+// sensitiveFlagValue returns the value of the assignment passed with a
+// sensitive flag, such as -var or -backend-config, in args[i].
+func sensitiveFlagValue(args []string, i int) (string, bool) {
+	var assignment string
+	switch arg := args[i]; {
+	case i > 0 && args[i-1] == "-var":
+		assignment = arg
+	case strings.HasPrefix(arg, "-var="):
+		assignment = strings.TrimPrefix(arg, "-var=")
+	case strings.HasPrefix(arg, "-backend-config="):
+		// values without an assignment are paths to backend configuration
+		// files
+		assignment = strings.TrimPrefix(arg, "-backend-config=")
+	default:
+		return "", false
+	}
+
+	_, value, ok := strings.Cut(assignment, "=")
+	return value, ok
+}
+
+// redactCmd returns the redacted equivalent of cmd.String().
+func (r *redactor) redactCmd(cmd *exec.Cmd) string {
+	return strings.Join(append([]string{cmd.Path}, r.redactArgs(cmd.Args[1:])...), " ")
+}
+
+func (r *redactor) redactDiagnostics(diags []tfjson.Diagnostic) []tfjson.Diagnostic {
+	if len(r.secrets) == 0 || len(diags) == 0 {
+		return diags
+	}
+
+	redacted := make([]tfjson.Diagnostic, len(diags))
+	for i, diag := range diags {
+		diag.Summary = r.redact(diag.Summary)
+		diag.Detail = r.redact(diag.Detail)
+		if diag.Snippet != nil {
+			snippet := *diag.Snippet
+			snippet.Code = r.redact(snippet.Code)
+			diag.Snippet = &snippet
+		}
+		redacted[i] = diag
+	}
+	return redacted
+}
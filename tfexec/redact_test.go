@@ -0,0 +1,147 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor([]string{"hunter2", "hunter2-extended"}, nil, []string{
+		"TF_TOKEN_app_example_com=abcdef123456",
+		"AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI",
+		"GITHUB_TOKEN=1",
+		`TF_VAR_db={"password":"db-secret-$${x}","port":5432}`,
+		"AWS_REGION=eu-west-1",
+	})
+
+	t.Run("text", func(t *testing.T) {
+		actual := r.redact("token abcdef123456, key wJalrXUtnFEMI, password hunter2-extended or hunter2 or db-secret-${x} in eu-west-1 with 1 token")
+		expected := "token [REDACTED], key [REDACTED], password [REDACTED] or [REDACTED] or [REDACTED] in eu-west-1 with 1 token"
+		if actual != expected {
+			t.Fatalf("expected %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("args", func(t *testing.T) {
+		actual := r.redactArgs([]string{
+			"init",
+			"-backend-config=backend.hcl",
+			"-backend-config=access_key=AKIA",
+			"-var", "region=eu-west-1",
+			"-var", "count=1",
+			"-var=password=secret",
+			"-var-file=prod.tfvars",
+			"-target=module.hunter2",
+		})
+		expected := []string{
+			"init",
+			"-backend-config=backend.hcl",
+			"-backend-config=access_key=[REDACTED]",
+			"-var", "region=[REDACTED]",
+			"-var", "count=[REDACTED]",
+			"-var=password=[REDACTED]",
+			"-var-file=prod.tfvars",
+			"-target=module.[REDACTED]",
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("diagnostics", func(t *testing.T) {
+		diags := []tfjson.Diagnostic{{
+			Severity: tfjson.DiagnosticSeverityError,
+			Summary:  "Invalid token abcdef123456",
+			Detail:   "The password hunter2 is invalid.",
+			Snippet:  &tfjson.DiagnosticSnippet{Code: `password = "hunter2"`},
+		}}
+
+		actual := r.redactDiagnostics(diags)
+		expected := []tfjson.Diagnostic{{
+			Severity: tfjson.DiagnosticSeverityError,
+			Summary:  "Invalid token [REDACTED]",
+			Detail:   "The password [REDACTED] is invalid.",
+			Snippet:  &tfjson.DiagnosticSnippet{Code: `password = "[REDACTED]"`},
+		}}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		// the original diagnostics are left unchanged
+		if diags[0].Snippet.Code != `password = "hunter2"` {
+			t.Fatalf("original diagnostics were modified: %q", diags[0].Snippet.Code)
+		}
+	})
+}
+
+func TestRedactor_flagValues(t *testing.T) {
+	r := newRedactor(nil, []string{
+		"plan",
+		"-var", "count=1000",
+		"-var", "enabled=true",
+		"-var=region=eu-west-1",
+		"-backend-config=token=backend-secret",
+		"-backend-config=port=5432",
+		"-backend-config=backend.hcl",
+	}, nil)
+
+	actual := r.redact("backend-secret, eu-west-1, backend.hcl, port 5432, count = 1000, enabled = true")
+	expected := "[REDACTED], eu-west-1, backend.hcl, port 5432, count = 1000, enabled = true"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestRedactor_words(t *testing.T) {
+	r := newRedactor([]string{"abcd", "-key-"}, nil, nil)
+
+	actual := r.redact("abcd abcde xabcd abcd_1 (abcd) a-key-b")
+	expected := "[REDACTED] abcde xabcd abcd_1 ([REDACTED]) a[REDACTED]b"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestRedactor_inheritedVars(t *testing.T) {
+	t.Setenv("TF_VAR_region", "eu-west-1")
+
+	r := newRedactor(nil, nil, []string{
+		"TF_VAR_region=eu-west-1",
+		"TF_VAR_db_pass=p4ssw0rd-value",
+		"TF_VAR_count=1000",
+	})
+
+	actual := r.redact("eu-west-1 p4ssw0rd-value 1000")
+	expected := "eu-west-1 [REDACTED] 1000"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestRedactor_encryption(t *testing.T) {
+	config, err := (&EncryptionConfig{
+		KeyProviders: []EncryptionKeyProvider{{
+			Type:   "pbkdf2",
+			Name:   "mykey",
+			Config: map[string]interface{}{"passphrase": "correct-horse-${battery}"},
+		}},
+	}).render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRedactor(nil, nil, []string{"TF_ENCRYPTION=" + config})
+
+	actual := r.redact(config + "passphrase correct-horse-${battery} of pbkdf2")
+	expected := "[REDACTED]passphrase [REDACTED] of pbkdf2"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
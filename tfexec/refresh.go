@@ -15,12 +15,14 @@ import (
 type refreshConfig struct {
 	backup       string
 	dir          string
+	excludes     []string
 	lock         bool
 	lockTimeout  string
 	reattachInfo ReattachInfo
 	state        string
 	stateOut     string
 	targets      []string
+	typedVars    []*VarValueOption
 	vars         []string
 	varFiles     []string
 }
@@ -67,6 +69,14 @@ func (opt *TargetOption) configureRefresh(conf *refreshConfig) {
 	conf.targets = append(conf.targets, opt.target)
 }
 
+func (opt *ExcludeOption) configureRefresh(conf *refreshConfig) {
+	conf.excludes = append(conf.excludes, opt.exclude)
+}
+
+func (opt *VarValueOption) configureRefresh(conf *refreshConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
 func (opt *VarOption) configureRefresh(conf *refreshConfig) {
 	conf.vars = append(conf.vars, opt.assignment)
 }
@@ -89,13 +99,26 @@ func (tf *Tofu) Refresh(ctx context.Context, opts ...RefreshCmdOption) error {
 // JSON being written to the supplied `io.Writer`. RefreshJSON is likely to be
 // removed in a future major version in favour of Refresh returning JSON by default.
 func (tf *Tofu) RefreshJSON(ctx context.Context, w io.Writer, opts ...RefreshCmdOption) error {
-	tf.SetStdout(w)
+	cmd, err := tf.refreshJSONCmd(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = mergeWriters(cmd.Stdout, w)
 
+	return tf.runTofuCmd(ctx, cmd)
+}
+
+// RefreshEvents represents the tofu refresh subcommand with the `-json` flag.
+// The handler is called with every machine-readable UI message as it is
+// emitted, see Event for the available message types.
+func (tf *Tofu) RefreshEvents(ctx context.Context, handler EventHandler, opts ...RefreshCmdOption) error {
 	cmd, err := tf.refreshJSONCmd(ctx, opts...)
 	if err != nil {
 		return err
 	}
 
+	cmd.Stdout = mergeWriters(cmd.Stdout, newEventWriter(handler))
+
 	return tf.runTofuCmd(ctx, cmd)
 }
 
@@ -154,22 +177,37 @@ func (tf *Tofu) buildRefreshArgs(c refreshConfig) []string {
 			args = append(args, "-target="+ta)
 		}
 	}
+	if c.excludes != nil {
+		for _, ex := range c.excludes {
+			args = append(args, "-exclude="+ex)
+		}
+	}
 	if c.vars != nil {
 		for _, v := range c.vars {
 			args = append(args, "-var", v)
 		}
 	}
+	args = append(args, typedVarArgs(c.typedVars)...)
 
 	return args
 }
 
 func (tf *Tofu) buildRefreshCmd(ctx context.Context, c refreshConfig, args []string) (*exec.Cmd, error) {
+	err := tf.checkExcludes(ctx, c.targets, c.excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	// optional positional argument
 	if c.dir != "" {
 		args = append(args, c.dir)
 	}
 
 	mergeEnv := map[string]string{}
+	err = addTypedVarEnv(mergeEnv, c.typedVars)
+	if err != nil {
+		return nil, err
+	}
 	if c.reattachInfo != nil {
 		reattachStr, err := c.reattachInfo.marshalString()
 		if err != nil {
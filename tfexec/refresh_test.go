@@ -61,6 +61,29 @@ func TestRefreshCmd(t *testing.T) {
 			"refreshdir",
 		}, nil, refreshCmd)
 	})
+	t.Run("exclude", func(t *testing.T) {
+		refreshCmd, err := tf.refreshCmd(context.Background(), Exclude("null_resource.foo"), Exclude("module.bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"refresh",
+			"-no-color",
+			"-input=false",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"-exclude=null_resource.foo",
+			"-exclude=module.bar",
+		}, nil, refreshCmd)
+	})
+
+	t.Run("exclude with target", func(t *testing.T) {
+		_, err := tf.refreshCmd(context.Background(), Target("null_resource.foo"), Exclude("module.bar"))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
 }
 
 func TestRefreshJSONCmd(t *testing.T) {
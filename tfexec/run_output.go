@@ -0,0 +1,54 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"io"
+)
+
+type outputContextKey struct{}
+
+type runOutput struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// WithOutput returns a copy of ctx which makes the commands run with it
+// stream their stdout and stderr to the given writers, in addition to any
+// writers configured with SetStdout and SetStderr. Either writer may be nil.
+//
+// Unlike SetStdout and SetStderr, this only affects the commands run with the
+// returned context, so it can be used by concurrent callers sharing the same
+// Tofu instance.
+//
+// As with SetStdout and SetStderr, this should be used for information or
+// logging purposes only, not control flow.
+func WithOutput(ctx context.Context, stdout io.Writer, stderr io.Writer) context.Context {
+	return context.WithValue(ctx, outputContextKey{}, runOutput{
+		stdout: stdout,
+		stderr: stderr,
+	})
+}
+
+// outputWriters returns the writers to stream the stdout and stderr of a
+// command run with ctx to. Either may be nil.
+func (tf *Tofu) outputWriters(ctx context.Context) (stdout io.Writer, stderr io.Writer) {
+	tf.mu.RLock()
+	stdout, stderr = tf.stdout, tf.stderr
+	tf.mu.RUnlock()
+
+	if o, ok := ctx.Value(outputContextKey{}).(runOutput); ok {
+		if o.stdout != nil {
+			stdout = mergeWriters(stdout, o.stdout)
+		}
+		if o.stderr != nil {
+			stderr = mergeWriters(stderr, o.stderr)
+		}
+	}
+
+	return stdout, stderr
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"time"
+)
+
+type resultContextKey struct{}
+
+// RunResult describes a completed OpenTofu command, see WithResult.
+type RunResult struct {
+	// RunID identifies the command in the records logged for it, see
+	// SetSlogLogger.
+	RunID string
+
+	// Args are the arguments the command was run with, excluding the path of
+	// the executable.
+	Args []string
+
+	// ExitCode is the exit code of the process, or -1 if it was terminated
+	// by a signal.
+	ExitCode int
+
+	// Duration is the wall time from starting the process until it exited
+	// and its output was read.
+	Duration time.Duration
+
+	// UserTime and SystemTime are the CPU time consumed by the process and
+	// its waited-for children, such as provider plugins.
+	UserTime   time.Duration
+	SystemTime time.Duration
+
+	// MaxRSS is the peak resident set size in bytes of the process or its
+	// largest waited-for child. It is only available on Linux, and zero
+	// elsewhere.
+	MaxRSS int64
+
+	// Stdout and Stderr hold the complete output of the command, including
+	// warnings printed by successful commands. Secrets are redacted from
+	// them, as well as from Args, see AddSecrets.
+	Stdout []byte
+	Stderr []byte
+}
+
+// WithResult returns a copy of ctx which makes the commands run with it fill
+// in result once they complete, whether they succeeded or not. It is not
+// filled in if the process could not be started.
+//
+// If a method runs several commands, result describes its main command: the
+// plan for PlanDetailed, the last plan for DetectDrift, and the apply for
+// PlanAndApply, or the plan if nothing was applied. Commands which only
+// inspect the plan, state or version on the side, such as the tofu show run
+// by PlanDetailed or the checks of the Policies and Provenance options, are
+// not recorded. Use a separate result per method call; a result must not be
+// shared between concurrent calls.
+func WithResult(ctx context.Context, result *RunResult) context.Context {
+	return context.WithValue(ctx, resultContextKey{}, result)
+}
+
+// withoutResult returns a copy of ctx for running a command on the side of
+// the main command of a method, which is not recorded, see WithResult.
+func withoutResult(ctx context.Context) context.Context {
+	if ctx.Value(resultContextKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, resultContextKey{}, (*RunResult)(nil))
+}
+
+// runRecorder captures the output and resource usage of a command run with a
+// context passed to WithResult. A nil *runRecorder records nothing.
+type runRecorder struct {
+	result *RunResult
+	start  time.Time
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+}
+
+func newRunRecorder(ctx context.Context) *runRecorder {
+	result, ok := ctx.Value(resultContextKey{}).(*RunResult)
+	if !ok || result == nil {
+		return nil
+	}
+	return &runRecorder{result: result}
+}
+
+// writers returns the writers to capture the stdout and stderr of the command
+// with, which are nil if nothing is recorded.
+func (r *runRecorder) writers() (stdout io.Writer, stderr io.Writer) {
+	if r == nil {
+		return nil, nil
+	}
+	return &r.stdout, &r.stderr
+}
+
+func (r *runRecorder) started() {
+	if r == nil {
+		return
+	}
+	r.start = time.Now()
+}
+
+// finish fills in the result once the command has exited, redacting any
+// secrets.
+func (r *runRecorder) finish(cmd *exec.Cmd, runID string, redactor *redactor) {
+	if r == nil || cmd.ProcessState == nil {
+		return
+	}
+
+	state := cmd.ProcessState
+	*r.result = RunResult{
+		RunID:      runID,
+		Args:       redactor.redactArgs(cmd.Args[1:]),
+		ExitCode:   state.ExitCode(),
+		Duration:   time.Since(r.start),
+		UserTime:   state.UserTime(),
+		SystemTime: state.SystemTime(),
+		MaxRSS:     maxRSS(state),
+		Stdout:     redactor.redactBytes(r.stdout.Bytes()),
+		Stderr:     redactor.redactBytes(r.stderr.Bytes()),
+	}
+}
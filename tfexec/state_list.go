@@ -0,0 +1,92 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+type stateListConfig struct {
+	addresses []string
+	id        string
+	state     string
+}
+
+var defaultStateListOptions = stateListConfig{}
+
+// StateListCmdOption represents options used in the StateList method.
+type StateListCmdOption interface {
+	configureStateList(*stateListConfig)
+}
+
+func (opt *AddressOption) configureStateList(conf *stateListConfig) {
+	conf.addresses = append(conf.addresses, opt.address)
+}
+
+func (opt *IDOption) configureStateList(conf *stateListConfig) {
+	conf.id = opt.id
+}
+
+func (opt *StateOption) configureStateList(conf *stateListConfig) {
+	conf.state = opt.path
+}
+
+// StateList represents the tofu state list subcommand.
+//
+// It returns the addresses of all resource instances in the state, optionally
+// filtered by AddressOption (an address or address prefix such as a module
+// path) and IDOption.
+func (tf *Tofu) StateList(ctx context.Context, opts ...StateListCmdOption) ([]string, error) {
+	cmd, err := tf.stateListCmd(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var outBuf strings.Builder
+	cmd.Stdout = mergeWriters(cmd.Stdout, &outBuf)
+
+	err = tf.runTofuCmd(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{}
+	lines := strings.Split(strings.ReplaceAll(outBuf.String(), "\r\n", "\n"), "\n")
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		addresses = append(addresses, l)
+	}
+
+	return addresses, nil
+}
+
+func (tf *Tofu) stateListCmd(ctx context.Context, opts ...StateListCmdOption) (*exec.Cmd, error) {
+	c := defaultStateListOptions
+
+	for _, o := range opts {
+		o.configureStateList(&c)
+	}
+
+	args := []string{"state", "list", "-no-color"}
+
+	// string opts: only pass if set
+	if c.id != "" {
+		args = append(args, "-id="+c.id)
+	}
+	if c.state != "" {
+		args = append(args, "-state="+c.state)
+	}
+
+	// optional positional arguments
+	args = append(args, c.addresses...)
+
+	return tf.buildTofuCmd(ctx, nil, args...), nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+)
+
+func TestStateListCmd(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// empty env, to avoid environ mismatch in testing
+	tf.SetEnv(map[string]string{})
+
+	t.Run("defaults", func(t *testing.T) {
+		stateListCmd, err := tf.stateListCmd(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"state",
+			"list",
+			"-no-color",
+		}, nil, stateListCmd)
+	})
+
+	t.Run("override all defaults", func(t *testing.T) {
+		stateListCmd, err := tf.stateListCmd(context.Background(), ID("testid"), State("teststate"), Address("module.foo"), Address("null_resource.bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"state",
+			"list",
+			"-no-color",
+			"-id=testid",
+			"-state=teststate",
+			"module.foo",
+			"null_resource.bar",
+		}, nil, stateListCmd)
+	})
+}
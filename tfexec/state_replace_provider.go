@@ -0,0 +1,89 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+)
+
+type stateReplaceProviderConfig struct {
+	backup      string
+	lock        bool
+	lockTimeout string
+	state       string
+}
+
+var defaultStateReplaceProviderOptions = stateReplaceProviderConfig{
+	lock:        true,
+	lockTimeout: "0s",
+}
+
+// StateReplaceProviderCmdOption represents options used in the
+// StateReplaceProvider method.
+type StateReplaceProviderCmdOption interface {
+	configureStateReplaceProvider(*stateReplaceProviderConfig)
+}
+
+func (opt *BackupOption) configureStateReplaceProvider(conf *stateReplaceProviderConfig) {
+	conf.backup = opt.path
+}
+
+func (opt *LockOption) configureStateReplaceProvider(conf *stateReplaceProviderConfig) {
+	conf.lock = opt.lock
+}
+
+func (opt *LockTimeoutOption) configureStateReplaceProvider(conf *stateReplaceProviderConfig) {
+	conf.lockTimeout = opt.timeout
+}
+
+func (opt *StateOption) configureStateReplaceProvider(conf *stateReplaceProviderConfig) {
+	conf.state = opt.path
+}
+
+// StateReplaceProvider represents the tofu state replace-provider subcommand.
+//
+// The from and to arguments are fully qualified provider addresses, for
+// example registry.terraform.io/hashicorp/aws. The replacement is always
+// approved automatically.
+func (tf *Tofu) StateReplaceProvider(ctx context.Context, from string, to string, opts ...StateReplaceProviderCmdOption) error {
+	cmd, err := tf.stateReplaceProviderCmd(ctx, from, to, opts...)
+	if err != nil {
+		return err
+	}
+	return tf.runTofuCmd(ctx, cmd)
+}
+
+func (tf *Tofu) stateReplaceProviderCmd(ctx context.Context, from string, to string, opts ...StateReplaceProviderCmdOption) (*exec.Cmd, error) {
+	c := defaultStateReplaceProviderOptions
+
+	for _, o := range opts {
+		o.configureStateReplaceProvider(&c)
+	}
+
+	args := []string{"state", "replace-provider", "-no-color", "-auto-approve"}
+
+	// string opts: only pass if set
+	if c.backup != "" {
+		args = append(args, "-backup="+c.backup)
+	}
+	if c.lockTimeout != "" {
+		args = append(args, "-lock-timeout="+c.lockTimeout)
+	}
+	if c.state != "" {
+		args = append(args, "-state="+c.state)
+	}
+
+	// boolean and numerical opts: always pass
+	args = append(args, "-lock="+strconv.FormatBool(c.lock))
+
+	// positional arguments
+	args = append(args, from)
+	args = append(args, to)
+
+	return tf.buildTofuCmd(ctx, nil, args...), nil
+}
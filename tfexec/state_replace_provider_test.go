@@ -0,0 +1,63 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
+)
+
+func TestStateReplaceProviderCmd(t *testing.T) {
+	td := t.TempDir()
+
+	tf, err := NewTofu(td, tfVersion(t, testutil.Latest_v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// empty env, to avoid environ mismatch in testing
+	tf.SetEnv(map[string]string{})
+
+	t.Run("defaults", func(t *testing.T) {
+		stateReplaceProviderCmd, err := tf.stateReplaceProviderCmd(context.Background(), "registry.terraform.io/hashicorp/null", "registry.opentofu.org/hashicorp/null")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"state",
+			"replace-provider",
+			"-no-color",
+			"-auto-approve",
+			"-lock-timeout=0s",
+			"-lock=true",
+			"registry.terraform.io/hashicorp/null",
+			"registry.opentofu.org/hashicorp/null",
+		}, nil, stateReplaceProviderCmd)
+	})
+
+	t.Run("override all defaults", func(t *testing.T) {
+		stateReplaceProviderCmd, err := tf.stateReplaceProviderCmd(context.Background(), "testfrom", "testto", Backup("testbackup"), LockTimeout("200s"), State("teststate"), Lock(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCmd(t, []string{
+			"state",
+			"replace-provider",
+			"-no-color",
+			"-auto-approve",
+			"-backup=testbackup",
+			"-lock-timeout=200s",
+			"-state=teststate",
+			"-lock=false",
+			"testfrom",
+			"testto",
+		}, nil, stateReplaceProviderCmd)
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+type stateShowConfig struct {
+	reattachInfo ReattachInfo
+	state        string
+}
+
+var defaultStateShowOptions = stateShowConfig{}
+
+// StateShowCmdOption represents options used in the StateShow method.
+type StateShowCmdOption interface {
+	configureStateShow(*stateShowConfig)
+}
+
+func (opt *ReattachOption) configureStateShow(conf *stateShowConfig) {
+	conf.reattachInfo = opt.info
+}
+
+func (opt *StateOption) configureStateShow(conf *stateShowConfig) {
+	conf.state = opt.path
+}
+
+// StateShow returns a single resource instance from the state.
+//
+// Unlike the human-readable output of tofu state show, the resource is read
+// from the JSON representation of the state, as returned by Show or
+// ShowStateFile when StateOption is given.
+func (tf *Tofu) StateShow(ctx context.Context, address string, opts ...StateShowCmdOption) (*tfjson.StateResource, error) {
+	if address == "" {
+		return nil, fmt.Errorf("address cannot be blank")
+	}
+
+	c := defaultStateShowOptions
+
+	for _, o := range opts {
+		o.configureStateShow(&c)
+	}
+
+	var showOpts []ShowOption
+	if c.reattachInfo != nil {
+		showOpts = append(showOpts, Reattach(c.reattachInfo))
+	}
+
+	var state *tfjson.State
+	var err error
+	if c.state != "" {
+		state, err = tf.ShowStateFile(ctx, c.state, showOpts...)
+	} else {
+		state, err = tf.Show(ctx, showOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resource := findStateResource(state, address)
+	if resource == nil {
+		return nil, fmt.Errorf("no resource instance found in state with address %q", address)
+	}
+
+	return resource, nil
+}
+
+// findStateResource returns the resource instance with the given address from
+// any module of the state, or nil if there is none.
+func findStateResource(state *tfjson.State, address string) *tfjson.StateResource {
+	if state == nil || state.Values == nil {
+		return nil
+	}
+
+	return findModuleResource(state.Values.RootModule, address)
+}
+
+func findModuleResource(module *tfjson.StateModule, address string) *tfjson.StateResource {
+	if module == nil {
+		return nil
+	}
+
+	for _, r := range module.Resources {
+		if r.Address == address {
+			return r
+		}
+	}
+	for _, child := range module.ChildModules {
+		if r := findModuleResource(child, address); r != nil {
+			return r
+		}
+	}
+
+	return nil
+}
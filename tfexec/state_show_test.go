@@ -0,0 +1,64 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestFindStateResource(t *testing.T) {
+	state := &tfjson.State{
+		Values: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				Resources: []*tfjson.StateResource{
+					{Address: "null_resource.foo"},
+				},
+				ChildModules: []*tfjson.StateModule{
+					{
+						Address: "module.child",
+						Resources: []*tfjson.StateResource{
+							{Address: "module.child.null_resource.bar[0]"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, c := range []struct {
+		address string
+		found   bool
+	}{
+		{"null_resource.foo", true},
+		{"module.child.null_resource.bar[0]", true},
+		{"module.child.null_resource.bar", false},
+		{"null_resource.baz", false},
+	} {
+		t.Run(c.address, func(t *testing.T) {
+			r := findStateResource(state, c.address)
+			if !c.found {
+				if r != nil {
+					t.Fatalf("expected no resource, got %q", r.Address)
+				}
+				return
+			}
+			if r == nil {
+				t.Fatal("expected resource, got none")
+			}
+			if r.Address != c.address {
+				t.Fatalf("expected %q, got %q", c.address, r.Address)
+			}
+		})
+	}
+
+	t.Run("empty state", func(t *testing.T) {
+		if r := findStateResource(&tfjson.State{}, "null_resource.foo"); r != nil {
+			t.Fatalf("expected no resource, got %q", r.Address)
+		}
+	})
+}
@@ -9,10 +9,18 @@ import (
 	"context"
 	"io"
 	"os/exec"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
 )
 
 type testConfig struct {
+	filters        []string
 	testsDirectory string
+	typedVars      []*VarValueOption
+	vars           []string
+	varFiles       []string
+	verbose        bool
 }
 
 var defaultTestOptions = testConfig{}
@@ -21,18 +29,37 @@ type TestOption interface {
 	configureTest(*testConfig)
 }
 
+func (opt *FilterOption) configureTest(conf *testConfig) {
+	conf.filters = append(conf.filters, opt.path)
+}
+
 func (opt *TestsDirectoryOption) configureTest(conf *testConfig) {
 	conf.testsDirectory = opt.testsDirectory
 }
 
+func (opt *VarValueOption) configureTest(conf *testConfig) {
+	conf.typedVars = append(conf.typedVars, opt)
+}
+
+func (opt *VarOption) configureTest(conf *testConfig) {
+	conf.vars = append(conf.vars, opt.assignment)
+}
+
+func (opt *VarFileOption) configureTest(conf *testConfig) {
+	conf.varFiles = append(conf.varFiles, opt.path)
+}
+
+func (opt *VerboseOption) configureTest(conf *testConfig) {
+	conf.verbose = opt.verbose
+}
+
 // Test represents the tofu test -json subcommand.
 //
 // The given io.Writer, if specified, will receive machine-readable
-// JSON from Terraform including test results.
-func (tf *Tofu) Test(ctx context.Context, w io.Writer, _ ...TestOption) error {
-	tf.SetStdout(w)
-
-	testCmd := tf.testCmd(ctx)
+// JSON from OpenTofu including test results.
+func (tf *Tofu) Test(ctx context.Context, w io.Writer, opts ...TestOption) error {
+	testCmd := tf.testCmd(ctx, opts...)
+	testCmd.Stdout = mergeWriters(testCmd.Stdout, w)
 
 	err := tf.runTofuCmd(ctx, testCmd)
 
@@ -43,6 +70,77 @@ func (tf *Tofu) Test(ctx context.Context, w io.Writer, _ ...TestOption) error {
 	return nil
 }
 
+// TestEvents represents the tofu test -json subcommand.
+//
+// The handler is called with every machine-readable UI message as it is
+// emitted, see Event for the available message types.
+func (tf *Tofu) TestEvents(ctx context.Context, handler EventHandler, opts ...TestOption) error {
+	return tf.runTestEventsCmd(ctx, nil, handler, opts)
+}
+
+// TestSummary is the result of a tofu test invocation.
+type TestSummary struct {
+	// Status is the overall status, TestStatusPass only if every run block
+	// passed.
+	Status  TestStatus
+	Passed  int
+	Failed  int
+	Errored int
+	Skipped int
+
+	// Files lists the test files in the order they were executed.
+	Files []*TestFileResult
+
+	// Diagnostics holds the diagnostics not related to a specific test file,
+	// such as configuration errors.
+	Diagnostics []tfjson.Diagnostic
+}
+
+// TestFileResult is the result of a single test file.
+type TestFileResult struct {
+	Path   string
+	Status TestStatus
+
+	// Runs lists the run blocks in the order they were executed.
+	Runs []*TestRunResult
+
+	// Diagnostics holds the diagnostics of the file not related to a
+	// specific run block.
+	Diagnostics []tfjson.Diagnostic
+}
+
+// TestRunResult is the result of a single run block.
+type TestRunResult struct {
+	Name        string
+	Status      TestStatus
+	Diagnostics []tfjson.Diagnostic
+}
+
+// TestResults represents the tofu test -json subcommand, returning the parsed
+// results of every test file and run block.
+//
+// The given io.Writer, if specified, will receive machine-readable
+// JSON from OpenTofu including test results.
+//
+// As tofu test exits with an error when any test fails, the summary is
+// returned along with the error whenever tests were executed. Check
+// TestSummary.Status to tell failed tests apart from other errors.
+func (tf *Tofu) TestResults(ctx context.Context, w io.Writer, opts ...TestOption) (*TestSummary, error) {
+	b := newTestSummaryBuilder()
+	err := tf.runTestEventsCmd(ctx, w, b.handle, opts)
+
+	return b.summary, err
+}
+
+// runTestEventsCmd runs tofu test -json, passing the machine-readable output
+// to w, if specified, and decoded into events to handler.
+func (tf *Tofu) runTestEventsCmd(ctx context.Context, w io.Writer, handler EventHandler, opts []TestOption) error {
+	testCmd := tf.testCmd(ctx, opts...)
+	testCmd.Stdout = mergeWriters(testCmd.Stdout, w, newEventWriter(handler))
+
+	return tf.runTofuCmd(ctx, testCmd)
+}
+
 func (tf *Tofu) testCmd(ctx context.Context, opts ...TestOption) *exec.Cmd {
 	c := defaultTestOptions
 
@@ -52,9 +150,131 @@ func (tf *Tofu) testCmd(ctx context.Context, opts ...TestOption) *exec.Cmd {
 
 	args := []string{"test", "-json"}
 
+	// string opts: only pass if set
 	if c.testsDirectory != "" {
-		args = append(args, "-tests-directory="+c.testsDirectory)
+		args = append(args, "-test-directory="+c.testsDirectory)
+	}
+	for _, vf := range c.varFiles {
+		args = append(args, "-var-file="+vf)
+	}
+
+	// unary flags: pass if true
+	if c.verbose {
+		args = append(args, "-verbose")
+	}
+
+	// string slice opts: split into separate args
+	for _, f := range c.filters {
+		args = append(args, "-filter="+f)
 	}
+	for _, v := range c.vars {
+		args = append(args, "-var", v)
+	}
+	args = append(args, typedVarArgs(c.typedVars)...)
+
+	mergeEnv := map[string]string{}
+	err := addTypedVarEnv(mergeEnv, c.typedVars)
+
+	cmd := tf.buildTofuCmd(ctx, mergeEnv, args...)
+	if err != nil {
+		// the error is returned when the command is started
+		cmd.Err = err
+	}
+	return cmd
+}
 
-	return tf.buildTofuCmd(ctx, nil, args...)
+// testSummaryBuilder assembles a TestSummary from the events emitted by
+// tofu test -json. The summary is nil until the first test event.
+type testSummaryBuilder struct {
+	summary *TestSummary
+	files   map[string]*TestFileResult
+
+	// abstract holds the run blocks of every test file, as announced before
+	// the tests are executed.
+	abstract map[string][]string
+}
+
+func newTestSummaryBuilder() *testSummaryBuilder {
+	return &testSummaryBuilder{
+		files: map[string]*TestFileResult{},
+	}
+}
+
+func (b *testSummaryBuilder) init() {
+	if b.summary == nil {
+		b.summary = &TestSummary{Status: TestStatusPending}
+	}
+}
+
+// file returns the result of the test file at path, registering it on its
+// first event so that the files are listed in the order they were executed.
+func (b *testSummaryBuilder) file(path string) *TestFileResult {
+	b.init()
+	f, ok := b.files[path]
+	if !ok {
+		f = &TestFileResult{Path: path, Status: TestStatusPending}
+		for _, name := range b.abstract[path] {
+			f.Runs = append(f.Runs, &TestRunResult{Name: name, Status: TestStatusPending})
+		}
+		b.files[path] = f
+		b.summary.Files = append(b.summary.Files, f)
+	}
+	return f
+}
+
+func (b *testSummaryBuilder) run(path string, name string) *TestRunResult {
+	f := b.file(path)
+	for _, r := range f.Runs {
+		if r.Name == name {
+			return r
+		}
+	}
+	r := &TestRunResult{Name: name, Status: TestStatusPending}
+	f.Runs = append(f.Runs, r)
+	return r
+}
+
+func (b *testSummaryBuilder) handle(ev Event) {
+	switch ev := ev.(type) {
+	case *TestAbstractEvent:
+		// TestAbstractEvent is a map, so the order in which the files are
+		// executed is only known from subsequent events, and files are
+		// registered as these arrive.
+		b.init()
+		b.abstract = ev.Abstract
+	case *TestFileEvent:
+		b.file(ev.File.Path).Status = ev.File.Status
+	case *TestRunEvent:
+		b.run(ev.Run.Path, ev.Run.Run).Status = ev.Run.Status
+	case *TestSummaryEvent:
+		// files without any events, for example because they were skipped,
+		// are listed last, sorted by path
+		b.init()
+		paths := make([]string, 0, len(b.abstract))
+		for path := range b.abstract {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			b.file(path)
+		}
+
+		b.summary.Status = ev.Summary.Status
+		b.summary.Passed = ev.Summary.Passed
+		b.summary.Failed = ev.Summary.Failed
+		b.summary.Errored = ev.Summary.Errored
+		b.summary.Skipped = ev.Summary.Skipped
+	case *DiagnosticEvent:
+		b.init()
+		switch {
+		case ev.TestFile != "" && ev.TestRun != "":
+			r := b.run(ev.TestFile, ev.TestRun)
+			r.Diagnostics = append(r.Diagnostics, ev.Diagnostic)
+		case ev.TestFile != "":
+			f := b.file(ev.TestFile)
+			f.Diagnostics = append(f.Diagnostics, ev.Diagnostic)
+		default:
+			b.summary.Diagnostics = append(b.summary.Diagnostics, ev.Diagnostic)
+		}
+	}
 }
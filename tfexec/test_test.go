@@ -9,6 +9,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+
 	"github.com/opentofu/tofu-exec/tfexec/internal/testutil"
 )
 
@@ -34,12 +37,122 @@ func TestTestCmd(t *testing.T) {
 	})
 
 	t.Run("override all defaults", func(t *testing.T) {
-		testCmd := tf.testCmd(context.Background(), TestsDirectory("test"))
+		testCmd := tf.testCmd(context.Background(),
+			TestsDirectory("test"),
+			VarFile("testfile"),
+			Verbose(true),
+			Filter("tests/a.tftest.hcl"),
+			Filter("tests/b.tftest.hcl"),
+			Var("var1=foo"),
+			Var("var2=bar"),
+		)
 
 		assertCmd(t, []string{
 			"test",
 			"-json",
-			"-tests-directory=test",
+			"-test-directory=test",
+			"-var-file=testfile",
+			"-verbose",
+			"-filter=tests/a.tftest.hcl",
+			"-filter=tests/b.tftest.hcl",
+			"-var", "var1=foo",
+			"-var", "var2=bar",
 		}, nil, testCmd)
 	})
 }
+
+func TestTestSummaryBuilder(t *testing.T) {
+	b := newTestSummaryBuilder()
+	w := newEventWriter(b.handle)
+
+	for _, line := range []string{
+		`{"@level":"info","@message":"Found 1 file and 2 run blocks","type":"test_abstract","test_abstract":{"main.tftest.hcl":["first","second"]}}`,
+		`{"@level":"info","@message":"main.tftest.hcl... in progress","@testfile":"main.tftest.hcl","type":"test_file","test_file":{"path":"main.tftest.hcl","status":"pending"}}`,
+		`{"@level":"info","@message":"  \"first\"... pass","@testfile":"main.tftest.hcl","@testrun":"first","type":"test_run","test_run":{"path":"main.tftest.hcl","run":"first","status":"pass"}}`,
+		`{"@level":"error","@message":"Error: Test assertion failed","@testfile":"main.tftest.hcl","@testrun":"second","type":"diagnostic","diagnostic":{"severity":"error","summary":"Test assertion failed","detail":"wrong value"}}`,
+		`{"@level":"info","@message":"  \"second\"... fail","@testfile":"main.tftest.hcl","@testrun":"second","type":"test_run","test_run":{"path":"main.tftest.hcl","run":"second","status":"fail"}}`,
+		`{"@level":"info","@message":"main.tftest.hcl... fail","@testfile":"main.tftest.hcl","type":"test_file","test_file":{"path":"main.tftest.hcl","status":"fail"}}`,
+		`{"@level":"info","@message":"Failure! 1 passed, 1 failed.","type":"test_summary","test_summary":{"status":"fail","passed":1,"failed":1,"errored":0,"skipped":0}}`,
+	} {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := &TestSummary{
+		Status: TestStatusFail,
+		Passed: 1,
+		Failed: 1,
+		Files: []*TestFileResult{
+			{
+				Path:   "main.tftest.hcl",
+				Status: TestStatusFail,
+				Runs: []*TestRunResult{
+					{
+						Name:   "first",
+						Status: TestStatusPass,
+					},
+					{
+						Name:   "second",
+						Status: TestStatusFail,
+						Diagnostics: []tfjson.Diagnostic{
+							{
+								Severity: tfjson.DiagnosticSeverityError,
+								Summary:  "Test assertion failed",
+								Detail:   "wrong value",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, b.summary); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTestSummaryBuilder_fileOrder(t *testing.T) {
+	b := newTestSummaryBuilder()
+	w := newEventWriter(b.handle)
+
+	for _, line := range []string{
+		`{"@level":"info","@message":"Found 3 files and 3 run blocks","type":"test_abstract","test_abstract":{"c.tftest.hcl":["third"],"a.tftest.hcl":["first"],"b.tftest.hcl":["second"]}}`,
+		`{"@level":"info","@message":"b.tftest.hcl... in progress","@testfile":"b.tftest.hcl","type":"test_file","test_file":{"path":"b.tftest.hcl","status":"pending"}}`,
+		`{"@level":"info","@message":"  \"second\"... pass","@testfile":"b.tftest.hcl","@testrun":"second","type":"test_run","test_run":{"path":"b.tftest.hcl","run":"second","status":"pass"}}`,
+		`{"@level":"info","@message":"b.tftest.hcl... pass","@testfile":"b.tftest.hcl","type":"test_file","test_file":{"path":"b.tftest.hcl","status":"pass"}}`,
+		`{"@level":"info","@message":"a.tftest.hcl... in progress","@testfile":"a.tftest.hcl","type":"test_file","test_file":{"path":"a.tftest.hcl","status":"pending"}}`,
+		`{"@level":"info","@message":"  \"first\"... pass","@testfile":"a.tftest.hcl","@testrun":"first","type":"test_run","test_run":{"path":"a.tftest.hcl","run":"first","status":"pass"}}`,
+		`{"@level":"info","@message":"a.tftest.hcl... pass","@testfile":"a.tftest.hcl","type":"test_file","test_file":{"path":"a.tftest.hcl","status":"pass"}}`,
+		`{"@level":"info","@message":"Success! 2 passed, 0 failed.","type":"test_summary","test_summary":{"status":"pass","passed":2,"failed":0,"errored":0,"skipped":0}}`,
+	} {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := &TestSummary{
+		Status: TestStatusPass,
+		Passed: 2,
+		Files: []*TestFileResult{
+			{
+				Path:   "b.tftest.hcl",
+				Status: TestStatusPass,
+				Runs:   []*TestRunResult{{Name: "second", Status: TestStatusPass}},
+			},
+			{
+				Path:   "a.tftest.hcl",
+				Status: TestStatusPass,
+				Runs:   []*TestRunResult{{Name: "first", Status: TestStatusPass}},
+			},
+			{
+				Path:   "c.tftest.hcl",
+				Status: TestStatusPending,
+				Runs:   []*TestRunResult{{Name: "third", Status: TestStatusPending}},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, b.summary); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
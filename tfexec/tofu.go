@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-version"
 )
@@ -30,12 +32,15 @@ type printfer interface {
 // have augmented our wrapped errors to respond true to errors.Is for context.DeadlineExceeded
 // and context.Canceled if those are present on the context when the error is parsed. See
 // https://github.com/golang/go/issues/21880 for more about the Go limitations.
+// See SetCancelGracePeriod for how the OpenTofu process is stopped when the
+// context is done.
 //
 // By default, the instance inherits the environment from the calling code (using os.Environ)
 // but it ignores certain environment variables that are managed within the code and prohibits
 // setting them through SetEnv:
 //
 //   - TF_APPEND_USER_AGENT
+//   - TF_ENCRYPTION
 //   - TF_IN_AUTOMATION
 //   - TF_INPUT
 //   - TF_LOG
@@ -43,7 +48,21 @@ type printfer interface {
 //   - TF_REATTACH_PROVIDERS
 //   - TF_DISABLE_PLUGIN_TLS
 //   - TF_SKIP_PROVIDER_VERIFY
+//
+// A Tofu instance is safe for concurrent use by multiple goroutines, like an
+// http.Client. Each command captures the configuration (environment, logging,
+// writers, etc.) when it starts, so calling one of the Set methods only
+// affects commands started afterwards. Note that OpenTofu itself may not
+// support concurrent commands against the same working directory, for
+// example due to state locking.
+//
+// Output that only concerns a single command should be passed through the
+// context with WithOutput rather than SetStdout and SetStderr.
 type Tofu struct {
+	// mu guards the fields below which can be changed after NewTofu through
+	// the Set methods.
+	mu sync.RWMutex
+
 	execPath           string
 	workingDir         string
 	appendUserAgent    string
@@ -51,9 +70,29 @@ type Tofu struct {
 	skipProviderVerify bool
 	env                map[string]string
 
-	stdout io.Writer
-	stderr io.Writer
-	logger printfer
+	// TF_ENCRYPTION environment variable, rendered from an EncryptionConfig
+	encryption string
+
+	// readOnly refuses commands which can change infrastructure or state
+	readOnly bool
+
+	// cancelGracePeriod is the time between interrupting and killing a
+	// cancelled command
+	cancelGracePeriod time.Duration
+
+	// secrets are redacted from the log, errors and captured output
+	secrets []string
+
+	// hooks wrap running every command
+	hooks []Hook
+
+	// tracer creates a span for every command
+	tracer Tracer
+
+	stdout  io.Writer
+	stderr  io.Writer
+	logger  printfer
+	slogger *slog.Logger
 
 	// TF_LOG environment variable, defaults to TRACE if logPath is set.
 	log string
@@ -111,34 +150,49 @@ func (tf *Tofu) SetEnv(env map[string]string) error {
 		return &ErrManualEnvVar{prohibited[0]}
 	}
 
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.env = env
 	return nil
 }
 
-// SetLogger specifies a logger for tfexec to use.
+// SetLogger specifies a logger for tfexec to use, replacing any structured
+// logger set with SetSlogLogger. The records logged by every command, see
+// SetSlogLogger, are printed as "[INFO] message key=value ...".
 func (tf *Tofu) SetLogger(logger printfer) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.logger = logger
+	tf.slogger = nil
 }
 
 // SetStdout specifies a writer to stream stdout to for every command.
+// Use WithOutput to stream the output of a single command instead.
 //
 // This should be used for information or logging purposes only, not control
 // flow. Any parsing necessary should be added as functionality to this package.
 func (tf *Tofu) SetStdout(w io.Writer) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.stdout = w
 }
 
 // SetStderr specifies a writer to stream stderr to for every command.
+// Use WithOutput to stream the output of a single command instead.
 //
 // This should be used for information or logging purposes only, not control
 // flow. Any parsing necessary should be added as functionality to this package.
 func (tf *Tofu) SetStderr(w io.Writer) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.stderr = w
 }
 
 // SetLog sets the TF_LOG environment variable for OpenTofu CLI execution.
 // This must be combined with a call to SetLogPath to take effect.
 func (tf *Tofu) SetLog(log string) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.log = log
 	return nil
 }
@@ -146,6 +200,8 @@ func (tf *Tofu) SetLog(log string) error {
 // SetLogCore sets the TF_LOG_CORE environment variable for OpenTofu CLI
 // execution. This must be combined with a call to SetLogPath to take effect.
 func (tf *Tofu) SetLogCore(logCore string) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.logCore = logCore
 	return nil
 }
@@ -153,6 +209,8 @@ func (tf *Tofu) SetLogCore(logCore string) error {
 // SetLogPath sets the TF_LOG_PATH environment variable for OpenTofu CLI
 // execution.
 func (tf *Tofu) SetLogPath(path string) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.logPath = path
 	// Prevent setting the log path without enabling logging
 	if tf.log == "" && tf.logCore == "" && tf.logProvider == "" {
@@ -165,6 +223,8 @@ func (tf *Tofu) SetLogPath(path string) error {
 // CLI execution. This must be combined with a call to SetLogPath to take
 // effect.
 func (tf *Tofu) SetLogProvider(logProvider string) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.logProvider = logProvider
 	return nil
 }
@@ -172,6 +232,8 @@ func (tf *Tofu) SetLogProvider(logProvider string) error {
 // SetAppendUserAgent sets the TF_APPEND_USER_AGENT environment variable for
 // OpenTofu CLI execution.
 func (tf *Tofu) SetAppendUserAgent(ua string) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.appendUserAgent = ua
 	return nil
 }
@@ -179,6 +241,8 @@ func (tf *Tofu) SetAppendUserAgent(ua string) error {
 // SetDisablePluginTLS sets the TF_DISABLE_PLUGIN_TLS environment variable for
 // OpenTofu CLI execution.
 func (tf *Tofu) SetDisablePluginTLS(disabled bool) error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
 	tf.disablePluginTLS = disabled
 	return nil
 }
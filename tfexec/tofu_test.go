@@ -48,6 +48,7 @@ func TestSetEnv(t *testing.T) {
 
 		{true, "TF_LOG"},
 		{true, "TF_VAR_foo"},
+		{true, "TF_ENCRYPTION"},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			err = tf.SetEnv(map[string]string{c.name: "foo"})
@@ -0,0 +1,204 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Tracer creates a span for every OpenTofu command, see SetTracer. The
+// oteltracer package adapts an OpenTelemetry TracerProvider.
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any.
+	Start(ctx context.Context, name string, attrs ...TraceAttribute) (context.Context, Span)
+}
+
+// Span is a span created by a Tracer.
+type Span interface {
+	// SpanContext returns the identity of the span, which is propagated to
+	// OpenTofu.
+	SpanContext() SpanContext
+
+	SetAttributes(attrs ...TraceAttribute)
+	RecordError(err error)
+	End()
+}
+
+// TraceAttribute is an attribute of a Span. Value is a string, int or bool.
+type TraceAttribute struct {
+	Key   string
+	Value any
+}
+
+// SpanContext identifies a span, as propagated by the W3C Trace Context
+// traceparent and tracestate headers.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid returns whether both the trace ID and span ID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent returns the W3C traceparent of the span, for example
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// ParseTraceParent parses a W3C traceparent. Future versions are parsed as
+// version 00, as required by the specification.
+func ParseTraceParent(traceParent string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 {
+		return sc, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || !isLowerHex(version) ||
+		(version == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent version in %q", traceParent)
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 ||
+		!isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return sc, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+
+	_, _ = hex.Decode(sc.TraceID[:], []byte(traceID))
+	_, _ = hex.Decode(sc.SpanID[:], []byte(spanID))
+	var f [1]byte
+	_, _ = hex.Decode(f[:], []byte(flags))
+	sc.Sampled = f[0]&0x01 != 0
+
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q: trace ID and span ID must not be zero", traceParent)
+	}
+	return sc, nil
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTracer enables tracing. Every command is run in a span named after its
+// subcommand, for example "tofu plan", which is a child of the span in the
+// context passed to the command.
+//
+// The span is propagated to OpenTofu through the TRACEPARENT and TRACESTATE
+// environment variables, so that the spans OpenTofu emits become its
+// children. OpenTofu emits spans if configured through the standard OTEL_*
+// environment variables, such as OTEL_TRACES_EXPORTER and
+// OTEL_EXPORTER_OTLP_ENDPOINT. While tracing is enabled, those of the calling
+// process are passed to OpenTofu even if the environment was replaced with
+// SetEnv, which can also set or override them.
+//
+// Pass nil to disable tracing.
+func (tf *Tofu) SetTracer(tracer Tracer) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.tracer = tracer
+}
+
+// commandSpan is the span of a command, which runTofuCmd starts before the
+// hooks and ends once the command ran.
+type commandSpan struct {
+	runID string
+	ctx   context.Context
+	span  Span
+}
+
+// startCommandSpan starts the span of the command running OpenTofu with the
+// given arguments. It returns nil if tracing is disabled.
+func startCommandSpan(ctx context.Context, tracer Tracer, workingDir string, args []string) *commandSpan {
+	if tracer == nil {
+		return nil
+	}
+
+	cs := &commandSpan{runID: newRunID()}
+	sub := subcommand(args)
+	cs.ctx, cs.span = tracer.Start(ctx, strings.TrimSpace("tofu "+sub),
+		TraceAttribute{Key: "tofu.run_id", Value: cs.runID},
+		TraceAttribute{Key: "tofu.subcommand", Value: sub},
+		TraceAttribute{Key: "tofu.working_dir", Value: workingDir},
+	)
+	return cs
+}
+
+// context returns ctx with the values of the context returned by the
+// Tracer, such as the span, so that hooks and loggers can access it.
+func (cs *commandSpan) context(ctx context.Context) context.Context {
+	return spanValuesContext{Context: ctx, values: cs.ctx}
+}
+
+// end ends the span of a command which returned err.
+func (cs *commandSpan) end(cmd *exec.Cmd, err error) {
+	if cs == nil {
+		return
+	}
+	cs.span.SetAttributes(TraceAttribute{Key: "tofu.exit_code", Value: exitCode(cmd)})
+	if err != nil {
+		cs.span.RecordError(err)
+	}
+	cs.span.End()
+}
+
+// traceEnv returns the environment of a command propagating span to
+// OpenTofu, so that the spans OpenTofu emits become its children.
+func traceEnv(env []string, span Span) []string {
+	m := envMap(env)
+
+	// export OpenTofu's spans like those of the calling process, unless
+	// explicitly overridden with tf.SetEnv or command env
+	for k, v := range envMap(os.Environ()) {
+		if _, ok := m[k]; !ok && strings.HasPrefix(k, otelEnvVarPrefix) {
+			m[k] = v
+		}
+	}
+
+	delete(m, traceParentEnvVar)
+	delete(m, traceStateEnvVar)
+	if sc := span.SpanContext(); sc.IsValid() {
+		m[traceParentEnvVar] = sc.TraceParent()
+		if sc.TraceState != "" {
+			m[traceStateEnvVar] = sc.TraceState
+		}
+	}
+
+	return envSlice(m)
+}
+
+// spanValuesContext looks up values in the context of a span before the
+// context it wraps, which determines cancellation.
+type spanValuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c spanValuesContext) Value(key any) any {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := SpanContext{
+		TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		Sampled: true,
+	}
+	if diff := cmp.Diff(expected, sc); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	if tp := sc.TraceParent(); tp != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected traceparent %q", tp)
+	}
+
+	t.Run("future version", func(t *testing.T) {
+		sc, err := ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc.Sampled {
+			t.Fatal("expected span not to be sampled")
+		}
+	})
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(invalid); err == nil {
+			t.Errorf("expected error parsing %q", invalid)
+		}
+	}
+}
+
+// memoryTracer records spans in memory.
+type memoryTracer struct {
+	mu    sync.Mutex
+	spans []*memorySpan
+}
+
+type memorySpanContextKey struct{}
+
+func (t *memoryTracer) Start(ctx context.Context, name string, attrs ...TraceAttribute) (context.Context, Span) {
+	span := &memorySpan{
+		name:  name,
+		attrs: map[string]any{},
+	}
+	if parent, ok := ctx.Value(memorySpanContextKey{}).(*memorySpan); ok {
+		span.parent = parent.sc
+		span.sc.TraceID = parent.sc.TraceID
+	} else {
+		_, _ = rand.Read(span.sc.TraceID[:])
+	}
+	_, _ = rand.Read(span.sc.SpanID[:])
+	span.sc.Sampled = true
+	span.SetAttributes(attrs...)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, memorySpanContextKey{}, span), span
+}
+
+type memorySpan struct {
+	name   string
+	sc     SpanContext
+	parent SpanContext
+	attrs  map[string]any
+	errs   []error
+	ended  bool
+}
+
+func (s *memorySpan) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *memorySpan) SetAttributes(attrs ...TraceAttribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *memorySpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *memorySpan) End() {
+	s.ended = true
+}
@@ -0,0 +1,146 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// encodeVarValue encodes a variable value the way OpenTofu parses values
+// passed with -var or TF_VAR_ environment variables: strings, numbers and
+// bools are taken literally, while other values are parsed as HCL
+// expressions. Complex values are therefore encoded as JSON, with template
+// sequences escaped, see encodeJSONVarValue.
+//
+// Null values are rejected, as OpenTofu reads a null passed this way as the
+// string "null" for string variables, but as null for other types.
+func encodeVarValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", errNullVarValue
+	case string:
+		return v, nil
+	case cty.Value:
+		return encodeCtyVarValue(v)
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if string(b) == "null" {
+		// such as a nil pointer, slice or map
+		return "", errNullVarValue
+	}
+	return encodeJSONVarValue(b), nil
+}
+
+var errNullVarValue = errors.New("null cannot be passed as a variable value, omit the variable to use its default instead")
+
+// encodeJSONVarValue returns the HCL expression equivalent to a JSON
+// document. JSON is a subset of the HCL expression syntax except for string
+// literals, which HCL parses as templates, so ${ and %{ are escaped like
+// quoteHCLString does. These sequences can only occur in strings of a JSON
+// document.
+func encodeJSONVarValue(b []byte) string {
+	return templateEscaper.Replace(string(b))
+}
+
+var (
+	templateEscaper   = strings.NewReplacer("${", "$${", "%{", "%%{")
+	templateUnescaper = strings.NewReplacer("$${", "${", "%%{", "%{")
+)
+
+// varValueStrings returns the strings contained in a variable value encoded
+// by encodeVarValue, such as the elements of a list or the attributes of an
+// object, so that they can be redacted on their own.
+func varValueStrings(value string) []string {
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil
+	}
+
+	var strs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			strs = append(strs, templateUnescaper.Replace(v))
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(decoded)
+	return strs
+}
+
+func encodeCtyVarValue(v cty.Value) (string, error) {
+	v, _ = v.UnmarkDeep()
+
+	if !v.IsWhollyKnown() {
+		return "", fmt.Errorf("unknown values cannot be passed as variable values")
+	}
+	if v.IsNull() {
+		return "", errNullVarValue
+	}
+
+	switch v.Type() {
+	case cty.String:
+		return v.AsString(), nil
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1), nil
+	case cty.Bool:
+		return strconv.FormatBool(v.True()), nil
+	}
+
+	b, err := ctyjson.Marshal(v, v.Type())
+	if err != nil {
+		return "", err
+	}
+	return encodeJSONVarValue(b), nil
+}
+
+// typedVarArgs returns the -var arguments for the values of VarValue options.
+// Values of SensitiveVar options are passed through the environment instead,
+// see addTypedVarEnv.
+func typedVarArgs(vars []*VarValueOption) []string {
+	var args []string
+	for _, v := range vars {
+		if v.sensitive || v.err != nil {
+			continue
+		}
+		args = append(args, "-var", v.name+"="+v.value)
+	}
+	return args
+}
+
+// addTypedVarEnv adds the TF_VAR_ environment variables for the values of
+// SensitiveVar options to env, and returns the first error encoding any
+// value.
+func addTypedVarEnv(env map[string]string, vars []*VarValueOption) error {
+	for _, v := range vars {
+		if v.err != nil {
+			return fmt.Errorf("unable to encode value of variable %q: %w", v.name, v.err)
+		}
+		if v.sensitive {
+			env[varEnvVarPrefix+v.name] = v.value
+		}
+	}
+	return nil
+}
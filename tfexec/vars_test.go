@@ -0,0 +1,82 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfexec
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEncodeVarValue(t *testing.T) {
+	type server struct {
+		Name  string `json:"name"`
+		Ports []int  `json:"ports"`
+	}
+
+	for _, c := range []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"string", `say "hello"`, `say "hello"`},
+		{"int", 42, "42"},
+		{"float", 0.5, "0.5"},
+		{"bool", true, "true"},
+		{"list", []string{"a", "b"}, `["a","b"]`},
+		{"map", map[string]any{"b": 1, "a": []bool{false}}, `{"a":[false],"b":1}`},
+		{"map with templates", map[string]string{"a": "${var.x}", "b": "%{ if true }$${y}"}, `{"a":"$${var.x}","b":"%%{ if true }$$${y}"}`},
+		{"string with template", "${var.x}", "${var.x}"},
+		{"struct", server{Name: "web", Ports: []int{80, 443}}, `{"name":"web","ports":[80,443]}`},
+		{"cty string", cty.StringVal("hello"), "hello"},
+		{"cty number", cty.NumberFloatVal(1.25), "1.25"},
+		{"cty bool", cty.False, "false"},
+		{"cty sensitive", cty.StringVal("secret").Mark("sensitive"), "secret"},
+		{"nested null", map[string]any{"a": nil}, `{"a":null}`},
+		{
+			"cty object",
+			cty.ObjectVal(map[string]cty.Value{
+				"name":  cty.StringVal("web"),
+				"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80)}),
+				"tags":  cty.MapVal(map[string]cty.Value{"env": cty.StringVal("prod")}),
+			}),
+			`{"name":"web","ports":[80],"tags":{"env":"prod"}}`,
+		},
+		{
+			"cty object with template",
+			cty.ObjectVal(map[string]cty.Value{
+				"${key}": cty.StringVal("%{value}"),
+			}),
+			`{"$${key}":"%%{value}"}`,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := encodeVarValue(c.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+
+	t.Run("null", func(t *testing.T) {
+		for _, value := range []any{nil, (*string)(nil), []string(nil), cty.NullVal(cty.String)} {
+			_, err := encodeVarValue(value)
+			if err == nil {
+				t.Fatalf("expected error for %#v, got none", value)
+			}
+		}
+	})
+
+	t.Run("cty unknown", func(t *testing.T) {
+		_, err := encodeVarValue(cty.ListVal([]cty.Value{cty.UnknownVal(cty.String)}))
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+}
@@ -17,6 +17,10 @@ import (
 	tfjson "github.com/hashicorp/terraform-json"
 )
 
+var (
+	tofu1_9_0 = version.Must(version.NewVersion("1.9.0"))
+)
+
 // Version returns structured output from the tofu version command including both the OpenTofu CLI version
 // and any initialized provider versions. This will read cached values when present unless the skipCache parameter
 // is set to true.
@@ -88,7 +92,7 @@ func errorVersionString(v *version.Version) string {
 
 // compatible asserts compatibility of the cached tofu version with the executable, and returns a well known error if not.
 func (tf *Tofu) compatible(ctx context.Context, minInclusive *version.Version, maxExclusive *version.Version) error {
-	tfv, _, err := tf.Version(ctx, false)
+	tfv, _, err := tf.Version(withoutResult(ctx), false)
 	if err != nil {
 		return err
 	}